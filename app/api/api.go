@@ -655,6 +655,24 @@ func (a *api) start() error {
 				}
 			}
 		}
+
+		if cfg.API.Auth.OIDC.Enable {
+			for _, t := range cfg.API.Auth.OIDC.Tenants {
+				validator, err := jwt.NewOIDCValidator(jwt.OIDCConfig{
+					Issuer:   t.Issuer,
+					Audience: t.Audience,
+					JWKSURL:  t.JWKSURL,
+					Users:    t.Users,
+				})
+				if err != nil {
+					return fmt.Errorf("unable to create OIDC JWT validator: %w", err)
+				}
+
+				if err := httpjwt.AddValidator(t.Issuer, validator); err != nil {
+					return fmt.Errorf("unable to add OIDC JWT validator: %w", err)
+				}
+			}
+		}
 	}
 
 	a.httpjwt = httpjwt
@@ -673,12 +691,15 @@ func (a *api) start() error {
 	metrics.Register(monitor.NewMemCollector())
 	metrics.Register(monitor.NewNetCollector())
 	metrics.Register(monitor.NewDiskCollector(a.diskfs.Metadata("base")))
+	metrics.Register(monitor.NewGPUCollector(nil))
 	metrics.Register(monitor.NewFilesystemCollector("diskfs", a.diskfs))
 	metrics.Register(monitor.NewFilesystemCollector("memfs", a.memfs))
 	for name, fs := range a.s3fs {
 		metrics.Register(monitor.NewFilesystemCollector(name, fs))
 	}
 	metrics.Register(monitor.NewRestreamCollector(a.restream))
+	metrics.Register(monitor.NewLimitsCollector(a.restream))
+	metrics.Register(monitor.NewProcessCollector(a.restream))
 	metrics.Register(monitor.NewFFmpegCollector(a.ffmpeg))
 	metrics.Register(monitor.NewSessionCollector(a.sessions, []string{}))
 
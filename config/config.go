@@ -116,6 +116,7 @@ func (d *Config) Clone() *Config {
 	data.API.Access.HTTPS.Block = copy.Slice(d.API.Access.HTTPS.Block)
 
 	data.API.Auth.Auth0.Tenants = copy.TenantSlice(d.API.Auth.Auth0.Tenants)
+	data.API.Auth.OIDC.Tenants = copy.OIDCTenantSlice(d.API.Auth.OIDC.Tenants)
 
 	data.Storage.CORS.Origins = copy.Slice(d.Storage.CORS.Origins)
 	data.Storage.Disk.Cache.Types.Allow = copy.Slice(d.Storage.Disk.Cache.Types.Allow)
@@ -177,6 +178,10 @@ func (d *Config) init() {
 	d.vars.Register(value.NewBool(&d.API.Auth.Auth0.Enable, false), "api.auth.auth0.enable", "CORE_API_AUTH_AUTH0_ENABLE", nil, "Enable Auth0", false, false)
 	d.vars.Register(value.NewTenantList(&d.API.Auth.Auth0.Tenants, []value.Auth0Tenant{}, ","), "api.auth.auth0.tenants", "CORE_API_AUTH_AUTH0_TENANTS", nil, "List of Auth0 tenants", false, false)
 
+	// Auth OIDC
+	d.vars.Register(value.NewBool(&d.API.Auth.OIDC.Enable, false), "api.auth.oidc.enable", "CORE_API_AUTH_OIDC_ENABLE", nil, "Enable OpenID Connect", false, false)
+	d.vars.Register(value.NewOIDCTenantList(&d.API.Auth.OIDC.Tenants, []value.OIDCTenant{}, ","), "api.auth.oidc.tenants", "CORE_API_AUTH_OIDC_TENANTS", nil, "List of OpenID Connect tenants", false, false)
+
 	// TLS
 	d.vars.Register(value.NewAddress(&d.TLS.Address, ":8181"), "tls.address", "CORE_TLS_ADDRESS", nil, "HTTPS listening address", false, false)
 	d.vars.Register(value.NewBool(&d.TLS.Enable, false), "tls.enable", "CORE_TLS_ENABLE", nil, "Enable HTTPS", false, false)
@@ -310,6 +315,19 @@ func (d *Config) Validate(resetLogs bool) {
 		}
 	}
 
+	// If OIDC is enabled, check that issuer and users are set
+	if d.API.Auth.OIDC.Enable {
+		if len(d.API.Auth.OIDC.Tenants) == 0 {
+			d.vars.Log("error", "api.auth.oidc.enable", "at least one tenants must be set")
+		}
+
+		for i, t := range d.API.Auth.OIDC.Tenants {
+			if len(t.Issuer) == 0 || len(t.Users) == 0 {
+				d.vars.Log("error", "api.auth.oidc.tenants", "issuer and users must be set (tenant %d)", i)
+			}
+		}
+	}
+
 	// If TLS is enabled and Let's Encrypt is disabled, require certfile and keyfile
 	if d.TLS.Enable && !d.TLS.Auto {
 		if len(d.TLS.CertFile) == 0 || len(d.TLS.KeyFile) == 0 {
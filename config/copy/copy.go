@@ -22,6 +22,16 @@ func TenantSlice(src []value.Auth0Tenant) []value.Auth0Tenant {
 	return dst
 }
 
+func OIDCTenantSlice(src []value.OIDCTenant) []value.OIDCTenant {
+	dst := Slice(src)
+
+	for i, t := range src {
+		dst[i].Users = Slice(t.Users)
+	}
+
+	return dst
+}
+
 func Slice[T any](src []T) []T {
 	dst := make([]T, len(src))
 	copy(dst, src)
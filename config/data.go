@@ -55,6 +55,10 @@ type Data struct {
 				Enable  bool                `json:"enable"`
 				Tenants []value.Auth0Tenant `json:"tenants"`
 			} `json:"auth0"`
+			OIDC struct {
+				Enable  bool               `json:"enable"`
+				Tenants []value.OIDCTenant `json:"tenants"`
+			} `json:"oidc"`
 		} `json:"auth"`
 	} `json:"api"`
 	TLS struct {
@@ -187,7 +191,14 @@ func MergeV2toV3(data *Data, d *v2.Data) (*Data, error) {
 	data.Log = d.Log
 	data.DB = d.DB
 	data.Host = d.Host
-	data.API = d.API
+	data.API.ReadOnly = d.API.ReadOnly
+	data.API.Access = d.API.Access
+	data.API.Auth.Enable = d.API.Auth.Enable
+	data.API.Auth.DisableLocalhost = d.API.Auth.DisableLocalhost
+	data.API.Auth.Username = d.API.Auth.Username
+	data.API.Auth.Password = d.API.Auth.Password
+	data.API.Auth.JWT = d.API.Auth.JWT
+	data.API.Auth.Auth0 = d.API.Auth.Auth0
 	data.RTMP = d.RTMP
 	data.SRT = d.SRT
 	data.FFmpeg = d.FFmpeg
@@ -270,7 +281,14 @@ func DowngradeV3toV2(d *Data) (*v2.Data, error) {
 	data.Log = d.Log
 	data.DB = d.DB
 	data.Host = d.Host
-	data.API = d.API
+	data.API.ReadOnly = d.API.ReadOnly
+	data.API.Access = d.API.Access
+	data.API.Auth.Enable = d.API.Auth.Enable
+	data.API.Auth.DisableLocalhost = d.API.Auth.DisableLocalhost
+	data.API.Auth.Username = d.API.Auth.Username
+	data.API.Auth.Password = d.API.Auth.Password
+	data.API.Auth.JWT = d.API.Auth.JWT
+	data.API.Auth.Auth0 = d.API.Auth.Auth0
 	data.RTMP = d.RTMP
 	data.SRT = d.SRT
 	data.FFmpeg = d.FFmpeg
@@ -13,6 +13,8 @@ func TestUpgrade(t *testing.T) {
 
 	v2cfg := v2.New(fs)
 	v2cfg.Storage.Disk.Cache.Types = []string{".foo", ".bar"}
+	v2cfg.API.Auth.Username = "foo"
+	v2cfg.API.Auth.Auth0.Enable = true
 
 	v3cfg, err := UpgradeV2ToV3(&v2cfg.Data, fs)
 
@@ -20,6 +22,9 @@ func TestUpgrade(t *testing.T) {
 	require.Equal(t, int64(3), v3cfg.Version)
 	require.ElementsMatch(t, []string{".foo", ".bar"}, v3cfg.Storage.Disk.Cache.Types.Allow)
 	require.ElementsMatch(t, []string{".m3u8", ".mpd"}, v3cfg.Storage.Disk.Cache.Types.Block)
+	require.Equal(t, "foo", v3cfg.API.Auth.Username)
+	require.True(t, v3cfg.API.Auth.Auth0.Enable)
+	require.False(t, v3cfg.API.Auth.OIDC.Enable)
 }
 
 func TestDowngrade(t *testing.T) {
@@ -27,10 +32,13 @@ func TestDowngrade(t *testing.T) {
 
 	v3cfg := New(fs)
 	v3cfg.Storage.Disk.Cache.Types.Allow = []string{".foo", ".bar"}
+	v3cfg.API.Auth.Username = "foo"
+	v3cfg.API.Auth.OIDC.Enable = true
 
 	v2cfg, err := DowngradeV3toV2(&v3cfg.Data)
 
 	require.NoError(t, err)
 	require.Equal(t, int64(2), v2cfg.Version)
 	require.ElementsMatch(t, []string{".foo", ".bar"}, v2cfg.Storage.Disk.Cache.Types)
+	require.Equal(t, "foo", v2cfg.API.Auth.Username)
 }
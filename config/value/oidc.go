@@ -0,0 +1,137 @@
+package value
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// array of OpenID Connect tenants
+
+type OIDCTenant struct {
+	Issuer   string   `json:"issuer"`
+	Audience string   `json:"audience"`
+	JWKSURL  string   `json:"jwks_url"`
+	Users    []string `json:"users"`
+}
+
+func (a *OIDCTenant) String() string {
+	issuer, err := url.Parse(a.Issuer)
+	if err != nil || issuer.Scheme != "https" {
+		data, _ := json.Marshal(a)
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	u := url.URL{
+		Scheme: "oidc",
+		Host:   issuer.Host,
+		Path:   issuer.Path,
+	}
+
+	q := url.Values{}
+
+	if len(a.Audience) != 0 {
+		q.Set("aud", a.Audience)
+	}
+
+	if len(a.JWKSURL) != 0 {
+		q.Set("jwks", a.JWKSURL)
+	}
+
+	for _, user := range a.Users {
+		q.Add("user", user)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+type OIDCTenantList struct {
+	p         *[]OIDCTenant
+	separator string
+}
+
+func NewOIDCTenantList(p *[]OIDCTenant, val []OIDCTenant, separator string) *OIDCTenantList {
+	v := &OIDCTenantList{
+		p:         p,
+		separator: separator,
+	}
+
+	*p = val
+
+	return v
+}
+
+// Set allows to set a tenant list in two formats:
+// - a separator separated list of bas64 encoded OIDCTenant JSON objects
+// - a separator separated list of OIDCTenant in URL representation: oidc://[issuer host and path]?aud=[audience]&jwks=[jwks url]&user=...&user=...
+// The URL representation is for issuers with a https URL.
+func (s *OIDCTenantList) Set(val string) error {
+	list := []OIDCTenant{}
+
+	for i, elm := range strings.Split(val, s.separator) {
+		t := OIDCTenant{}
+
+		if strings.HasPrefix(elm, "oidc://") {
+			data, err := url.Parse(elm)
+			if err != nil {
+				return fmt.Errorf("invalid url encoding of tenant %d: %w", i, err)
+			}
+
+			t.Issuer = "https://" + data.Host + data.Path
+			t.Audience = data.Query().Get("aud")
+			t.JWKSURL = data.Query().Get("jwks")
+			t.Users = data.Query()["user"]
+		} else {
+			data, err := base64.StdEncoding.DecodeString(elm)
+			if err != nil {
+				return fmt.Errorf("invalid base64 encoding of tenant %d: %w", i, err)
+			}
+
+			if err := json.Unmarshal(data, &t); err != nil {
+				return fmt.Errorf("invalid JSON in tenant %d: %w", i, err)
+			}
+		}
+
+		list = append(list, t)
+	}
+
+	*s.p = list
+
+	return nil
+}
+
+func (s *OIDCTenantList) String() string {
+	if s.IsEmpty() {
+		return "(empty)"
+	}
+
+	list := []string{}
+
+	for _, t := range *s.p {
+		list = append(list, t.String())
+	}
+
+	return strings.Join(list, s.separator)
+}
+
+func (s *OIDCTenantList) Validate() error {
+	for i, t := range *s.p {
+		if len(t.Issuer) == 0 {
+			return fmt.Errorf("the issuer for tenant %d is missing", i)
+		}
+
+		if _, err := url.Parse(t.Issuer); err != nil {
+			return fmt.Errorf("the issuer for tenant %d is invalid: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *OIDCTenantList) IsEmpty() bool {
+	return len(*s.p) == 0
+}
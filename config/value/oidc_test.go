@@ -0,0 +1,49 @@
+package value
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCValue(t *testing.T) {
+	tenants := []OIDCTenant{}
+
+	v := NewOIDCTenantList(&tenants, nil, " ")
+	require.Equal(t, "(empty)", v.String())
+
+	v.Set("oidc://keycloak.example.com/realms/core?aud=core&user=user1&user=user2 oidc://accounts.google.com?jwks=https%3A%2F%2Fwww.googleapis.com%2Foauth2%2Fv3%2Fcerts&user=user3")
+	require.Equal(t, []OIDCTenant{
+		{
+			Issuer:   "https://keycloak.example.com/realms/core",
+			Audience: "core",
+			Users:    []string{"user1", "user2"},
+		},
+		{
+			Issuer:  "https://accounts.google.com",
+			JWKSURL: "https://www.googleapis.com/oauth2/v3/certs",
+			Users:   []string{"user3"},
+		},
+	}, tenants)
+	require.Equal(t, "oidc://keycloak.example.com/realms/core?aud=core&user=user1&user=user2 oidc://accounts.google.com?jwks=https%3A%2F%2Fwww.googleapis.com%2Foauth2%2Fv3%2Fcerts&user=user3", v.String())
+	require.NoError(t, v.Validate())
+
+	data := base64.StdEncoding.EncodeToString([]byte(`{"issuer":"http://localhost:8080/","audience":"core","users":["user1"]}`))
+
+	v.Set(data)
+	require.Equal(t, []OIDCTenant{
+		{
+			Issuer:   "http://localhost:8080/",
+			Audience: "core",
+			Users:    []string{"user1"},
+		},
+	}, tenants)
+	require.NoError(t, v.Validate())
+
+	v.Set(v.String())
+	require.Equal(t, "http://localhost:8080/", tenants[0].Issuer)
+
+	tenants = []OIDCTenant{{Audience: "core"}}
+	require.Error(t, v.Validate())
+}
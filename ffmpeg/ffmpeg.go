@@ -29,18 +29,24 @@ type FFmpeg interface {
 }
 
 type ProcessConfig struct {
-	Reconnect      bool
-	ReconnectDelay time.Duration
-	StaleTimeout   time.Duration
-	LimitCPU       float64
-	LimitMemory    uint64
-	LimitDuration  time.Duration
-	Command        []string
-	Parser         process.Parser
-	Logger         log.Logger
-	OnExit         func()
-	OnStart        func()
-	OnStateChange  func(from, to string)
+	Reconnect         bool
+	ReconnectDelay    time.Duration
+	ReconnectDelayMax time.Duration
+	CrashLoopRestarts int
+	CrashLoopWindow   time.Duration
+	StaleTimeout      time.Duration
+	LimitCPU          float64
+	LimitMemory       uint64
+	LimitDuration     time.Duration
+	Command           []string
+	Parser            process.Parser
+	Logger            log.Logger
+	OnExit            func()
+	OnBeforeStart     func() error
+	OnStart           func()
+	OnGiveUp          func()
+	OnStateChange     func(from, to string)
+	OnArgs            func(args []string, state string, restart bool) []string
 }
 
 // Config is the configuration for ffmpeg that is part of the configuration
@@ -115,18 +121,24 @@ func New(config Config) (FFmpeg, error) {
 
 func (f *ffmpeg) New(config ProcessConfig) (process.Process, error) {
 	ffmpeg, err := process.New(process.Config{
-		Binary:         f.binary,
-		Args:           config.Command,
-		Reconnect:      config.Reconnect,
-		ReconnectDelay: config.ReconnectDelay,
-		StaleTimeout:   config.StaleTimeout,
-		LimitCPU:       config.LimitCPU,
-		LimitMemory:    config.LimitMemory,
-		LimitDuration:  config.LimitDuration,
-		Parser:         config.Parser,
-		Logger:         config.Logger,
-		OnStart:        config.OnStart,
-		OnExit:         config.OnExit,
+		Binary:            f.binary,
+		Args:              config.Command,
+		Reconnect:         config.Reconnect,
+		ReconnectDelay:    config.ReconnectDelay,
+		ReconnectDelayMax: config.ReconnectDelayMax,
+		CrashLoopRestarts: config.CrashLoopRestarts,
+		CrashLoopWindow:   config.CrashLoopWindow,
+		StaleTimeout:      config.StaleTimeout,
+		LimitCPU:          config.LimitCPU,
+		LimitMemory:       config.LimitMemory,
+		LimitDuration:     config.LimitDuration,
+		Parser:            config.Parser,
+		Logger:            config.Logger,
+		OnBeforeStart:     config.OnBeforeStart,
+		OnStart:           config.OnStart,
+		OnGiveUp:          config.OnGiveUp,
+		OnExit:            config.OnExit,
+		OnArgs:            config.OnArgs,
 		OnStateChange: func(from, to string) {
 			f.statesLock.Lock()
 			switch to {
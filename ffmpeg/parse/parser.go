@@ -641,6 +641,11 @@ func (p *parser) addLog(line string) {
 	p.lock.log.Lock()
 	defer p.lock.log.Unlock()
 
+	// No log lines are kept
+	if p.log == nil {
+		return
+	}
+
 	p.log.Value = process.Line{
 		Timestamp: time.Now(),
 		Data:      line,
@@ -11,6 +11,35 @@ type About struct {
 	Version   Version  `json:"version"`
 }
 
+// AboutOverview is the information about the API together with an overview
+// of the processes, their resource usage and the active sessions
+type AboutOverview struct {
+	About
+	Resources AboutResources `json:"resources"`
+	Processes AboutProcesses `json:"processes"`
+	Sessions  AboutSessions  `json:"sessions"`
+}
+
+// AboutResources is the summed up resource usage of all processes
+type AboutResources struct {
+	CPU    float64 `json:"cpu_usage"`
+	Memory uint64  `json:"memory_bytes" format:"uint64"`
+}
+
+// AboutProcesses is the number of all processes and of those that are running or failed
+type AboutProcesses struct {
+	Total   uint64 `json:"total" format:"uint64"`
+	Running uint64 `json:"running" format:"uint64"`
+	Failed  uint64 `json:"failed" format:"uint64"`
+}
+
+// AboutSessions is the number of currently active sessions per protocol
+type AboutSessions struct {
+	HLS  uint64 `json:"hls" format:"uint64"`
+	RTMP uint64 `json:"rtmp" format:"uint64"`
+	SRT  uint64 `json:"srt" format:"uint64"`
+}
+
 // Version is some information about the binary
 type Version struct {
 	Number   string `json:"number"`
@@ -7,5 +7,6 @@ type JWT struct {
 }
 
 type JWTRefresh struct {
-	AccessToken string `json:"access_token" jsonschema:"minLength=1"`
+	AccessToken  string `json:"access_token" jsonschema:"minLength=1"`
+	RefreshToken string `json:"refresh_token" jsonschema:"minLength=1"`
 }
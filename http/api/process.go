@@ -23,10 +23,11 @@ type Process struct {
 
 // ProcessConfigIO represents an input or output of an ffmpeg process config
 type ProcessConfigIO struct {
-	ID      string                   `json:"id"`
-	Address string                   `json:"address" validate:"required" jsonschema:"minLength=1"`
-	Options []string                 `json:"options"`
-	Cleanup []ProcessConfigIOCleanup `json:"cleanup,omitempty"`
+	ID       string                   `json:"id"`
+	Address  string                   `json:"address" validate:"required" jsonschema:"minLength=1"`
+	Failover []string                 `json:"failover,omitempty"`
+	Options  []string                 `json:"options"`
+	Cleanup  []ProcessConfigIOCleanup `json:"cleanup,omitempty"`
 }
 
 type ProcessConfigIOCleanup struct {
@@ -42,43 +43,85 @@ type ProcessConfigLimits struct {
 	WaitFor uint64  `json:"waitfor_seconds" jsonschema:"minimum=0" format:"uint64"`
 }
 
+// ProcessConfigHook represents a URL that gets notified about an event of a process
+type ProcessConfigHook struct {
+	URL      string `json:"url" validate:"required" jsonschema:"minLength=1"`
+	Timeout  uint64 `json:"timeout_seconds" format:"uint64"`
+	Blocking bool   `json:"blocking"`
+}
+
+func (h *ProcessConfigHook) marshal() *app.ConfigHook {
+	if h == nil {
+		return nil
+	}
+
+	return &app.ConfigHook{
+		URL:      h.URL,
+		Timeout:  h.Timeout,
+		Blocking: h.Blocking,
+	}
+}
+
+func unmarshalHook(h *app.ConfigHook) *ProcessConfigHook {
+	if h == nil {
+		return nil
+	}
+
+	return &ProcessConfigHook{
+		URL:      h.URL,
+		Timeout:  h.Timeout,
+		Blocking: h.Blocking,
+	}
+}
+
 // ProcessConfig represents the configuration of an ffmpeg process
 type ProcessConfig struct {
-	ID             string              `json:"id"`
-	Type           string              `json:"type" validate:"oneof='ffmpeg' ''" jsonschema:"enum=ffmpeg,enum="`
-	Reference      string              `json:"reference"`
-	Input          []ProcessConfigIO   `json:"input" validate:"required"`
-	Output         []ProcessConfigIO   `json:"output" validate:"required"`
-	Options        []string            `json:"options"`
-	Reconnect      bool                `json:"reconnect"`
-	ReconnectDelay uint64              `json:"reconnect_delay_seconds" format:"uint64"`
-	Autostart      bool                `json:"autostart"`
-	StaleTimeout   uint64              `json:"stale_timeout_seconds" format:"uint64"`
-	Limits         ProcessConfigLimits `json:"limits"`
+	ID                string              `json:"id"`
+	Type              string              `json:"type" validate:"oneof='ffmpeg' ''" jsonschema:"enum=ffmpeg,enum="`
+	Reference         string              `json:"reference"`
+	Input             []ProcessConfigIO   `json:"input" validate:"required"`
+	Output            []ProcessConfigIO   `json:"output" validate:"required"`
+	Options           []string            `json:"options"`
+	Reconnect         bool                `json:"reconnect"`
+	ReconnectDelay    uint64              `json:"reconnect_delay_seconds" format:"uint64"`
+	ReconnectDelayMax uint64              `json:"reconnect_delay_max_seconds" format:"uint64"`
+	CrashLoopRestarts uint64              `json:"crashloop_restarts" format:"uint64"`
+	CrashLoopWindow   uint64              `json:"crashloop_window_seconds" format:"uint64"`
+	Autostart         bool                `json:"autostart"`
+	StaleTimeout      uint64              `json:"stale_timeout_seconds" format:"uint64"`
+	Limits            ProcessConfigLimits `json:"limits"`
+	PreStart          *ProcessConfigHook  `json:"pre_start,omitempty"`
+	PostStop          *ProcessConfigHook  `json:"post_stop,omitempty"`
 }
 
 // Marshal converts a process config in API representation to a restreamer process config
 func (cfg *ProcessConfig) Marshal() *app.Config {
 	p := &app.Config{
-		ID:             cfg.ID,
-		Reference:      cfg.Reference,
-		Options:        cfg.Options,
-		Reconnect:      cfg.Reconnect,
-		ReconnectDelay: cfg.ReconnectDelay,
-		Autostart:      cfg.Autostart,
-		StaleTimeout:   cfg.StaleTimeout,
-		LimitCPU:       cfg.Limits.CPU,
-		LimitMemory:    cfg.Limits.Memory * 1024 * 1024,
-		LimitWaitFor:   cfg.Limits.WaitFor,
+		ID:                cfg.ID,
+		Reference:         cfg.Reference,
+		Options:           cfg.Options,
+		Reconnect:         cfg.Reconnect,
+		ReconnectDelay:    cfg.ReconnectDelay,
+		ReconnectDelayMax: cfg.ReconnectDelayMax,
+		CrashLoopRestarts: cfg.CrashLoopRestarts,
+		CrashLoopWindow:   cfg.CrashLoopWindow,
+		Autostart:         cfg.Autostart,
+		StaleTimeout:      cfg.StaleTimeout,
+		LimitCPU:          cfg.Limits.CPU,
+		LimitMemory:       cfg.Limits.Memory * 1024 * 1024,
+		LimitWaitFor:      cfg.Limits.WaitFor,
+		PreStart:          cfg.PreStart.marshal(),
+		PostStop:          cfg.PostStop.marshal(),
 	}
 
 	cfg.generateInputOutputIDs(cfg.Input)
 
 	for _, x := range cfg.Input {
 		p.Input = append(p.Input, app.ConfigIO{
-			ID:      x.ID,
-			Address: x.Address,
-			Options: x.Options,
+			ID:       x.ID,
+			Address:  x.Address,
+			Failover: x.Failover,
+			Options:  x.Options,
 		})
 	}
 
@@ -144,11 +187,16 @@ func (cfg *ProcessConfig) Unmarshal(c *app.Config) {
 	cfg.Type = "ffmpeg"
 	cfg.Reconnect = c.Reconnect
 	cfg.ReconnectDelay = c.ReconnectDelay
+	cfg.ReconnectDelayMax = c.ReconnectDelayMax
+	cfg.CrashLoopRestarts = c.CrashLoopRestarts
+	cfg.CrashLoopWindow = c.CrashLoopWindow
 	cfg.Autostart = c.Autostart
 	cfg.StaleTimeout = c.StaleTimeout
 	cfg.Limits.CPU = c.LimitCPU
 	cfg.Limits.Memory = c.LimitMemory / 1024 / 1024
 	cfg.Limits.WaitFor = c.LimitWaitFor
+	cfg.PreStart = unmarshalHook(c.PreStart)
+	cfg.PostStop = unmarshalHook(c.PostStop)
 
 	cfg.Options = make([]string, len(c.Options))
 	copy(cfg.Options, c.Options)
@@ -162,6 +210,11 @@ func (cfg *ProcessConfig) Unmarshal(c *app.Config) {
 		io.Options = make([]string, len(x.Options))
 		copy(io.Options, x.Options)
 
+		if len(x.Failover) != 0 {
+			io.Failover = make([]string, len(x.Failover))
+			copy(io.Failover, x.Failover)
+		}
+
 		cfg.Input = append(cfg.Input, io)
 	}
 
@@ -234,15 +287,38 @@ func (report *ProcessReport) Unmarshal(l *app.Log) {
 
 // ProcessState represents the current state of an ffmpeg process
 type ProcessState struct {
-	Order     string      `json:"order" jsonschema:"enum=start,enum=stop"`
-	State     string      `json:"exec" jsonschema:"enum=finished,enum=starting,enum=running,enum=finishing,enum=killed,enum=failed"`
-	Runtime   int64       `json:"runtime_seconds" jsonschema:"minimum=0" format:"int64"`
-	Reconnect int64       `json:"reconnect_seconds" format:"int64"`
-	LastLog   string      `json:"last_logline"`
-	Progress  *Progress   `json:"progress"`
-	Memory    uint64      `json:"memory_bytes" format:"uint64"`
-	CPU       json.Number `json:"cpu_usage" swaggertype:"number" jsonschema:"type=number"`
-	Command   []string    `json:"command"`
+	Order     string               `json:"order" jsonschema:"enum=start,enum=stop"`
+	State     string               `json:"exec" jsonschema:"enum=finished,enum=starting,enum=running,enum=finishing,enum=killed,enum=failed"`
+	Runtime   int64                `json:"runtime_seconds" jsonschema:"minimum=0" format:"int64"`
+	Reconnect int64                `json:"reconnect_seconds" format:"int64"`
+	CrashLoop bool                 `json:"crashloop"`
+	LastLog   string               `json:"last_logline"`
+	Progress  *Progress            `json:"progress"`
+	Memory    uint64               `json:"memory_bytes" format:"uint64"`
+	CPU       json.Number          `json:"cpu_usage" swaggertype:"number" jsonschema:"type=number"`
+	Sources   []ProcessInputSource `json:"sources,omitempty"`
+	Hooks     ProcessHooks         `json:"hooks"`
+	Command   []string             `json:"command"`
+}
+
+// ProcessInputSource represents the currently active address of an input with failover addresses
+type ProcessInputSource struct {
+	ID        string `json:"id"`
+	Address   string `json:"address"`
+	Index     int    `json:"index"`
+	Failovers uint64 `json:"failovers" format:"uint64"`
+}
+
+// ProcessHookResult represents the result of the last execution of a hook
+type ProcessHookResult struct {
+	Time  int64  `json:"time" format:"int64"`
+	Error string `json:"error"`
+}
+
+// ProcessHooks represents the results of the hooks of a process
+type ProcessHooks struct {
+	PreStart ProcessHookResult `json:"pre_start"`
+	PostStop ProcessHookResult `json:"post_stop"`
 }
 
 // Unmarshal converts a restreamer ffmpeg process state to a state in API representation
@@ -255,11 +331,30 @@ func (s *ProcessState) Unmarshal(state *app.State) {
 	s.State = state.State
 	s.Runtime = int64(state.Duration)
 	s.Reconnect = int64(state.Reconnect)
+	s.CrashLoop = state.CrashLoop
 	s.LastLog = state.LastLog
 	s.Progress = &Progress{}
 	s.Memory = state.Memory
 	s.CPU = toNumber(state.CPU)
 	s.Command = state.Command
 
+	s.Hooks.PreStart = ProcessHookResult{
+		Time:  state.Hooks.PreStart.Time,
+		Error: state.Hooks.PreStart.Error,
+	}
+	s.Hooks.PostStop = ProcessHookResult{
+		Time:  state.Hooks.PostStop.Time,
+		Error: state.Hooks.PostStop.Error,
+	}
+
+	for _, x := range state.Sources {
+		s.Sources = append(s.Sources, ProcessInputSource{
+			ID:        x.ID,
+			Address:   x.Address,
+			Index:     x.Index,
+			Failovers: x.Failovers,
+		})
+	}
+
 	s.Progress.Unmarshal(&state.Progress)
 }
@@ -75,6 +75,13 @@ type SessionSummary struct {
 
 type SessionsSummary map[string]SessionSummary
 
+// SessionLimits are the limits for new sessions of a collector. Limits that
+// are not set are left unchanged.
+type SessionLimits struct {
+	MaxSessions  *uint64 `json:"max_sessions,omitempty" format:"uint64"`
+	MaxTxBitrate *uint64 `json:"max_bandwidth_tx_mbit,omitempty" format:"uint64"` // mbit/s
+}
+
 // SessionsActive is the API representation of all active sessions
 type SessionsActive map[string][]Session
 
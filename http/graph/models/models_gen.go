@@ -333,6 +333,49 @@ func (e Command) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ProcessOrder string
+
+const (
+	ProcessOrderID        ProcessOrder = "ID"
+	ProcessOrderCreatedAt ProcessOrder = "CREATED_AT"
+	ProcessOrderCPU       ProcessOrder = "CPU"
+)
+
+var AllProcessOrder = []ProcessOrder{
+	ProcessOrderID,
+	ProcessOrderCreatedAt,
+	ProcessOrderCPU,
+}
+
+func (e ProcessOrder) IsValid() bool {
+	switch e {
+	case ProcessOrderID, ProcessOrderCreatedAt, ProcessOrderCPU:
+		return true
+	}
+	return false
+}
+
+func (e ProcessOrder) String() string {
+	return string(e)
+}
+
+func (e *ProcessOrder) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProcessOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProcessOrder", str)
+	}
+	return nil
+}
+
+func (e ProcessOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type State string
 
 const (
@@ -6,13 +6,22 @@ package resolver
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/datarhei/core/v16/http/graph/models"
 )
 
 // Processes is the resolver for the processes field.
-func (r *queryResolver) Processes(ctx context.Context) ([]*models.Process, error) {
-	ids := r.Restream.GetProcessIDs("", "")
+func (r *queryResolver) Processes(ctx context.Context, limit *int, offset *int, orderBy *models.ProcessOrder) ([]*models.Process, error) {
+	ids, err := r.getProcessIDs(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err = paginate(ids, limit, offset)
+	if err != nil {
+		return nil, err
+	}
 
 	procs := []*models.Process{}
 
@@ -28,6 +37,11 @@ func (r *queryResolver) Processes(ctx context.Context) ([]*models.Process, error
 	return procs, nil
 }
 
+// ProcessCount is the resolver for the processCount field.
+func (r *queryResolver) ProcessCount(ctx context.Context) (int, error) {
+	return len(r.Restream.GetProcessIDs("", "")), nil
+}
+
 // Process is the resolver for the process field.
 func (r *queryResolver) Process(ctx context.Context, id string) (*models.Process, error) {
 	return r.getProcess(id)
@@ -42,3 +56,36 @@ func (r *queryResolver) Probe(ctx context.Context, id string) (*models.Probe, er
 
 	return p, nil
 }
+
+// ProcessCommand is the resolver for the processCommand field.
+func (r *mutationResolver) ProcessCommand(ctx context.Context, id string, command models.Command) (bool, error) {
+	var err error
+
+	switch command {
+	case models.CommandStart:
+		err = r.Restream.StartProcess(id)
+	case models.CommandStop:
+		err = r.Restream.StopProcess(id)
+	case models.CommandRestart:
+		err = r.Restream.RestartProcess(id)
+	case models.CommandReload:
+		err = r.Restream.ReloadProcess(id)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnknownCommand, command)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteProcess is the resolver for the deleteProcess field.
+func (r *mutationResolver) DeleteProcess(ctx context.Context, id string) (bool, error) {
+	if err := r.Restream.DeleteProcess(id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
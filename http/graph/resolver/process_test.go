@@ -0,0 +1,216 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datarhei/core/v16/http/graph/graph"
+	"github.com/datarhei/core/v16/http/graph/models"
+	"github.com/datarhei/core/v16/http/mock"
+	"github.com/datarhei/core/v16/restream/app"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/require"
+)
+
+func getDummyResolver(t *testing.T, ids ...string) *Resolver {
+	rs, err := mock.DummyRestreamer("../../mock")
+	require.NoError(t, err)
+
+	if len(ids) == 0 {
+		ids = []string{"process"}
+	}
+
+	for _, id := range ids {
+		err = rs.AddProcess(&app.Config{
+			ID: id,
+			Input: []app.ConfigIO{
+				{
+					ID:      "in",
+					Address: "testsrc=size=1280x720:rate=25",
+					Options: []string{"-f", "lavfi", "-re"},
+				},
+			},
+			Output: []app.ConfigIO{
+				{
+					ID:      "out",
+					Address: "-",
+					Options: []string{"-codec", "copy", "-f", "null"},
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	return &Resolver{
+		Restream: rs,
+	}
+}
+
+func TestProcesses(t *testing.T) {
+	r := getDummyResolver(t, "c", "a", "d", "b", "e")
+	q := r.Query()
+
+	processIDs := func(procs []*models.Process) []string {
+		ids := []string{}
+		for _, p := range procs {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	procs, err := q.Processes(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, processIDs(procs))
+
+	limit, offset := 2, 1
+
+	procs, err = q.Processes(context.Background(), &limit, &offset, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, processIDs(procs))
+
+	offset = 4
+
+	procs, err = q.Processes(context.Background(), &limit, &offset, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e"}, processIDs(procs))
+
+	offset = 10
+
+	procs, err = q.Processes(context.Background(), &limit, &offset, nil)
+	require.NoError(t, err)
+	require.Empty(t, procs)
+
+	limit = -1
+
+	_, err = q.Processes(context.Background(), &limit, nil, nil)
+	require.Error(t, err)
+
+	count, err := q.ProcessCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+}
+
+func TestProcessesOrder(t *testing.T) {
+	r := getDummyResolver(t, "c", "a", "b")
+	q := r.Query()
+
+	order := models.ProcessOrderCreatedAt
+
+	procs, err := q.Processes(context.Background(), nil, nil, &order)
+	require.NoError(t, err)
+	require.Len(t, procs, 3)
+
+	for i := 1; i < len(procs); i++ {
+		require.False(t, procs[i].CreatedAt.Before(procs[i-1].CreatedAt))
+	}
+
+	order = models.ProcessOrderCPU
+
+	procs, err = q.Processes(context.Background(), nil, nil, &order)
+	require.NoError(t, err)
+	require.Len(t, procs, 3)
+
+	for i := 1; i < len(procs); i++ {
+		require.GreaterOrEqual(t, procs[i-1].State.CPUUsage, procs[i].State.CPUUsage)
+	}
+
+	order = models.ProcessOrder("FOOBAR")
+
+	_, err = q.Processes(context.Background(), nil, nil, &order)
+	require.Error(t, err)
+}
+
+func TestProcessCommand(t *testing.T) {
+	r := getDummyResolver(t)
+	m := r.Mutation()
+
+	ok, err := m.ProcessCommand(context.Background(), "process", models.CommandStart)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	state, err := r.Restream.GetProcessState("process")
+	require.NoError(t, err)
+	require.Equal(t, "start", state.Order)
+
+	ok, err = m.ProcessCommand(context.Background(), "process", models.CommandStop)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	state, err = r.Restream.GetProcessState("process")
+	require.NoError(t, err)
+	require.Equal(t, "stop", state.Order)
+
+	_, err = m.ProcessCommand(context.Background(), "foobar", models.CommandStart)
+	require.Error(t, err)
+}
+
+func TestProcessCommandUnknown(t *testing.T) {
+	r := getDummyResolver(t)
+
+	ok, err := r.Mutation().ProcessCommand(context.Background(), "process", models.Command("FOOBAR"))
+	require.ErrorIs(t, err, ErrUnknownCommand)
+	require.False(t, ok)
+}
+
+func TestDeleteProcess(t *testing.T) {
+	r := getDummyResolver(t)
+
+	ok, err := r.Mutation().DeleteProcess(context.Background(), "process")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = r.Restream.GetProcess("process")
+	require.Error(t, err)
+
+	_, err = r.Mutation().DeleteProcess(context.Background(), "process")
+	require.Error(t, err)
+}
+
+func TestProcessMutationQuery(t *testing.T) {
+	r := getDummyResolver(t)
+
+	server := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: r}))
+
+	query := func(q string) map[string]interface{} {
+		data, err := json.Marshal(map[string]string{"query": q})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		server.ServeHTTP(rec, req)
+
+		res := map[string]interface{}{}
+		err = json.Unmarshal(rec.Body.Bytes(), &res)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	res := query(`mutation { processCommand(id: "process", command: RESTART) }`)
+	require.Nil(t, res["errors"])
+	require.Equal(t, map[string]interface{}{"processCommand": true}, res["data"])
+
+	res = query(`mutation { processCommand(id: "process", command: FOOBAR) }`)
+	require.NotNil(t, res["errors"])
+
+	res = query(`{ processes(limit: 1, orderBy: ID) { id } processCount }`)
+	require.Nil(t, res["errors"])
+	require.Equal(t, map[string]interface{}{
+		"processes":    []interface{}{map[string]interface{}{"id": "process"}},
+		"processCount": float64(1),
+	}, res["data"])
+
+	res = query(`mutation { deleteProcess(id: "process") }`)
+	require.Nil(t, res["errors"])
+	require.Equal(t, map[string]interface{}{"deleteProcess": true}, res["data"])
+
+	res = query(`mutation { deleteProcess(id: "process") }`)
+	require.NotNil(t, res["errors"])
+}
@@ -2,8 +2,11 @@ package resolver
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/datarhei/core/v16/http/graph/models"
@@ -16,12 +19,101 @@ import (
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
+// ErrUnknownCommand is returned if a process command is not known.
+var ErrUnknownCommand = errors.New("unknown command")
+
 type Resolver struct {
 	Restream  restream.Restreamer
 	Monitor   monitor.HistoryReader
 	LogBuffer log.BufferWriter
 }
 
+// getProcessIDs returns the IDs of all processes in the given order. Processes are ordered
+// by their ID by default. The CPU order lists the processes with the highest CPU usage first.
+func (r *queryResolver) getProcessIDs(orderBy *models.ProcessOrder) ([]string, error) {
+	ids := r.Restream.GetProcessIDs("", "")
+
+	order := models.ProcessOrderID
+	if orderBy != nil {
+		order = *orderBy
+	}
+
+	switch order {
+	case models.ProcessOrderID:
+		sort.Strings(ids)
+	case models.ProcessOrderCreatedAt:
+		createdAt := map[string]int64{}
+
+		for _, id := range ids {
+			process, err := r.Restream.GetProcess(id)
+			if err != nil {
+				return nil, err
+			}
+
+			createdAt[id] = process.CreatedAt
+		}
+
+		sort.Slice(ids, func(i, j int) bool {
+			if createdAt[ids[i]] == createdAt[ids[j]] {
+				return ids[i] < ids[j]
+			}
+
+			return createdAt[ids[i]] < createdAt[ids[j]]
+		})
+	case models.ProcessOrderCPU:
+		cpu := map[string]float64{}
+
+		for _, id := range ids {
+			state, err := r.Restream.GetProcessState(id)
+			if err != nil {
+				return nil, err
+			}
+
+			cpu[id] = state.CPU
+		}
+
+		sort.Slice(ids, func(i, j int) bool {
+			if cpu[ids[i]] == cpu[ids[j]] {
+				return ids[i] < ids[j]
+			}
+
+			return cpu[ids[i]] > cpu[ids[j]]
+		})
+	default:
+		return nil, fmt.Errorf("unknown order: %s", order)
+	}
+
+	return ids, nil
+}
+
+// paginate returns the part of the ids as selected by limit and offset. A missing
+// limit returns all ids from the offset on.
+func paginate(ids []string, limit, offset *int) ([]string, error) {
+	if offset != nil {
+		if *offset < 0 {
+			return nil, fmt.Errorf("offset must not be negative")
+		}
+
+		if *offset >= len(ids) {
+			return []string{}, nil
+		}
+
+		ids = ids[*offset:]
+	}
+
+	if limit != nil {
+		if *limit < 0 {
+			return nil, fmt.Errorf("limit must not be negative")
+		}
+
+		if *limit < len(ids) {
+			ids = ids[:*limit]
+		}
+	}
+
+	return ids, nil
+}
+
 func (r *queryResolver) getProcess(id string) (*models.Process, error) {
 	process, err := r.Restream.GetProcess(id)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/datarhei/core/v16/app"
 	"github.com/datarhei/core/v16/http/api"
 	"github.com/datarhei/core/v16/restream"
+	"github.com/datarhei/core/v16/session"
 
 	"github.com/labstack/echo/v4"
 )
@@ -16,13 +17,37 @@ import (
 type AboutHandler struct {
 	restream restream.Restreamer
 	auths    []string
+	registry session.RegistryReader
+}
+
+type AboutConfig struct {
+	Restream restream.Restreamer
+	Auths    []string
+	Registry session.RegistryReader
 }
 
 // NewAbout returns a new About type
 func NewAbout(restream restream.Restreamer, auths []string) *AboutHandler {
+	return NewAboutWithConfig(AboutConfig{
+		Restream: restream,
+		Auths:    auths,
+	})
+}
+
+// NewAboutWithConfig returns a new About type with the given config
+func NewAboutWithConfig(config AboutConfig) *AboutHandler {
+	if config.Auths == nil {
+		config.Auths = []string{}
+	}
+
+	if config.Registry == nil {
+		config.Registry, _ = session.New(session.Config{})
+	}
+
 	return &AboutHandler{
-		restream: restream,
-		auths:    auths,
+		restream: config.Restream,
+		auths:    config.Auths,
+		registry: config.Registry,
 	}
 }
 
@@ -35,6 +60,50 @@ func NewAbout(restream restream.Restreamer, auths []string) *AboutHandler {
 // @Security ApiKeyAuth
 // @Router /api [get]
 func (p *AboutHandler) About(c echo.Context) error {
+	return c.JSON(http.StatusOK, p.about())
+}
+
+// Overview returns API version and build infos together with an overview of the processes and sessions
+// @Summary API version and build infos with an overview of processes and sessions
+// @Description API version and build infos, the summed up resource usage of all processes, the number of processes and the number of active sessions.
+// @Tags v16.16.0
+// @ID about-3-overview
+// @Produce json
+// @Success 200 {object} api.AboutOverview
+// @Security ApiKeyAuth
+// @Router /api/v3/about [get]
+func (p *AboutHandler) Overview(c echo.Context) error {
+	overview := api.AboutOverview{
+		About: p.about(),
+	}
+
+	for _, id := range p.restream.GetProcessIDs("", "") {
+		state, err := p.restream.GetProcessState(id)
+		if err != nil {
+			continue
+		}
+
+		overview.Processes.Total++
+
+		switch state.State {
+		case "running":
+			overview.Processes.Running++
+		case "failed":
+			overview.Processes.Failed++
+		}
+
+		overview.Resources.CPU += state.Resources.CPU.Current
+		overview.Resources.Memory += state.Resources.Memory.Current
+	}
+
+	overview.Sessions.HLS = p.registry.Summary("hls").CurrentSessions
+	overview.Sessions.RTMP = p.registry.Summary("rtmp").CurrentSessions
+	overview.Sessions.SRT = p.registry.Summary("srt").CurrentSessions
+
+	return c.JSON(http.StatusOK, overview)
+}
+
+func (p *AboutHandler) about() api.About {
 	createdAt := p.restream.CreatedAt()
 
 	about := api.About{
@@ -54,5 +123,5 @@ func (p *AboutHandler) About(c echo.Context) error {
 		},
 	}
 
-	return c.JSON(http.StatusOK, about)
+	return about
 }
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 
 	"github.com/datarhei/core/v16/http/api"
 	"github.com/datarhei/core/v16/http/mock"
+	"github.com/datarhei/core/v16/session"
 	"github.com/stretchr/testify/require"
 
 	"github.com/labstack/echo/v4"
@@ -34,3 +36,40 @@ func TestAbout(t *testing.T) {
 
 	mock.Validate(t, &api.About{}, response.Data)
 }
+
+func TestAboutOverview(t *testing.T) {
+	router := mock.DummyEcho()
+
+	rs, err := mock.DummyRestreamer("../../mock")
+	require.NoError(t, err)
+
+	registry, err := session.New(session.Config{})
+	require.NoError(t, err)
+
+	collector, err := registry.Register("hls", session.CollectorConfig{})
+	require.NoError(t, err)
+
+	collector.RegisterAndActivate("foo", "ref", "location", "peer")
+
+	handler := NewAboutWithConfig(AboutConfig{
+		Restream: rs,
+		Registry: registry,
+	})
+
+	router.Add("GET", "/", handler.Overview)
+
+	response := mock.Request(t, http.StatusOK, router, "GET", "/", nil)
+
+	mock.Validate(t, &api.AboutOverview{}, response.Data)
+
+	data, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+
+	overview := api.AboutOverview{}
+	err = json.Unmarshal(data, &overview)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(1), overview.Sessions.HLS)
+	require.Equal(t, uint64(0), overview.Sessions.SRT)
+	require.Equal(t, uint64(len(rs.GetProcessIDs("", ""))), overview.Processes.Total)
+}
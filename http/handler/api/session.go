@@ -48,6 +48,58 @@ func (s *SessionHandler) Summary(c echo.Context) error {
 	return c.JSON(http.StatusOK, sessionsSummary)
 }
 
+// SetLimits sets the limits for new sessions
+// @Summary Set the limits for new sessions
+// @Description Set the max. number of sessions and the max. egress bandwidth of the given collectors. The limits apply to new sessions, already existing sessions are not affected. A value of 0 means no limit. Limits that are not set are left unchanged.
+// @Tags v16.16.0
+// @ID session-3-set-limits
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param collectors query string true "Comma separated list of collectors"
+// @Param limits body api.SessionLimits true "Session limits"
+// @Success 200 {object} api.SessionLimits
+// @Failure 400 {object} api.Error
+// @Failure 404 {object} api.Error
+// @Router /api/v3/session/limits [put]
+func (s *SessionHandler) SetLimits(c echo.Context) error {
+	query := util.DefaultQuery(c, "collectors", "")
+	if len(query) == 0 {
+		return api.Err(http.StatusBadRequest, "Missing collectors", "at least one collector is required")
+	}
+
+	collectors := strings.Split(query, ",")
+
+	limits := api.SessionLimits{}
+
+	if err := util.ShouldBindJSON(c, &limits); err != nil {
+		return api.Err(http.StatusBadRequest, "Invalid JSON", "%s", err)
+	}
+
+	list := []session.Collector{}
+
+	for _, name := range collectors {
+		collector := s.registry.Collector(name)
+		if collector == nil {
+			return api.Err(http.StatusNotFound, "Collector not found", "unknown collector: %s", name)
+		}
+
+		list = append(list, collector)
+	}
+
+	for _, collector := range list {
+		if limits.MaxSessions != nil {
+			collector.SetMaxSessions(*limits.MaxSessions)
+		}
+
+		if limits.MaxTxBitrate != nil {
+			collector.SetMaxEgressBitrate(*limits.MaxTxBitrate * 1024 * 1024)
+		}
+	}
+
+	return c.JSON(http.StatusOK, limits)
+}
+
 // Active returns a list of active sessions
 // @Summary Get a minimal summary of all active sessions
 // @Description Get a minimal summary of all active sessions (i.e. number of sessions, bandwidth).
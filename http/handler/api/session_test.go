@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/datarhei/core/v16/session"
 
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
 )
 
 func getDummySessionRouter() *echo.Echo {
@@ -24,6 +27,7 @@ func getDummySessionRouter() *echo.Echo {
 
 	router.Add("GET", "/summary", handler.Summary)
 	router.Add("GET", "/active", handler.Active)
+	router.Add("PUT", "/limits", handler.SetLimits)
 
 	return router
 }
@@ -43,3 +47,86 @@ func TestSessionActive(t *testing.T) {
 
 	mock.Validate(t, api.SessionsActive{}, response.Data)
 }
+
+func TestSessionSetLimits(t *testing.T) {
+	router := mock.DummyEcho()
+
+	registry, _ := session.New(session.Config{})
+	collector, _ := registry.Register("foo", session.CollectorConfig{})
+
+	handler := NewSession(registry)
+
+	router.Add("PUT", "/limits", handler.SetLimits)
+
+	maxSessions := uint64(1)
+	maxTxBitrate := uint64(2)
+
+	data := bytes.Buffer{}
+	json.NewEncoder(&data).Encode(api.SessionLimits{
+		MaxSessions:  &maxSessions,
+		MaxTxBitrate: &maxTxBitrate,
+	})
+
+	response := mock.Request(t, http.StatusOK, router, "PUT", "/limits?collectors=foo", &data)
+
+	mock.Validate(t, &api.SessionLimits{}, response.Data)
+
+	require.Equal(t, float64(2*1024*1024), collector.MaxEgressBitrate())
+	require.False(t, collector.IsSessionsExceeded())
+
+	collector.RegisterAndActivate("foobar", "", "any", "any")
+	require.True(t, collector.IsSessionsExceeded())
+
+	maxSessions = 0
+	maxTxBitrate = 0
+
+	data.Reset()
+	json.NewEncoder(&data).Encode(api.SessionLimits{
+		MaxSessions:  &maxSessions,
+		MaxTxBitrate: &maxTxBitrate,
+	})
+
+	mock.Request(t, http.StatusOK, router, "PUT", "/limits?collectors=foo", &data)
+	require.False(t, collector.IsSessionsExceeded())
+	require.Equal(t, float64(0), collector.MaxEgressBitrate())
+
+	data.Reset()
+	json.NewEncoder(&data).Encode(api.SessionLimits{})
+
+	mock.Request(t, http.StatusNotFound, router, "PUT", "/limits?collectors=bar", &data)
+
+	data.Reset()
+	json.NewEncoder(&data).Encode(api.SessionLimits{})
+
+	mock.Request(t, http.StatusBadRequest, router, "PUT", "/limits", &data)
+}
+
+func TestSessionSetLimitsPartial(t *testing.T) {
+	router := mock.DummyEcho()
+
+	registry, _ := session.New(session.Config{})
+	collector, _ := registry.Register("foo", session.CollectorConfig{})
+
+	handler := NewSession(registry)
+
+	router.Add("PUT", "/limits", handler.SetLimits)
+
+	data := bytes.NewBufferString(`{"max_sessions":1,"max_bandwidth_tx_mbit":2}`)
+	mock.Request(t, http.StatusOK, router, "PUT", "/limits?collectors=foo", data)
+
+	// Only the max. number of sessions is changed
+	data = bytes.NewBufferString(`{"max_sessions":10}`)
+	mock.Request(t, http.StatusOK, router, "PUT", "/limits?collectors=foo", data)
+
+	require.Equal(t, float64(2*1024*1024), collector.MaxEgressBitrate())
+
+	collector.RegisterAndActivate("foobar", "", "any", "any")
+	require.False(t, collector.IsSessionsExceeded())
+
+	// Only the max. egress bandwidth is changed
+	data = bytes.NewBufferString(`{"max_bandwidth_tx_mbit":0}`)
+	mock.Request(t, http.StatusOK, router, "PUT", "/limits?collectors=foo", data)
+
+	require.Equal(t, float64(0), collector.MaxEgressBitrate())
+	require.False(t, collector.IsSessionsExceeded())
+}
@@ -41,6 +41,10 @@ type JWT interface {
 
 	// RefreshHandle is an echo route handler for refreshing a JWT
 	RefreshHandler(c echo.Context) error
+
+	// RevokeJWT revokes the token with the jti claim. A revoked token will not
+	// be accepted anymore.
+	RevokeJWT(jti string)
 }
 
 type jwt struct {
@@ -57,6 +61,10 @@ type jwt struct {
 	// the "iss" field in the claims. Somewhat required because otherwise the token cannot be verified.
 	validators map[string]Validator
 	lock       sync.RWMutex
+	// Revoked is a map of the jti claim of all revoked tokens to the time they expire. Expired
+	// tokens are removed from the map, because they will not be accepted anyways.
+	revoked     map[string]time.Time
+	revokedLock sync.Mutex
 }
 
 // New returns a new JWT provider
@@ -67,6 +75,7 @@ func New(config Config) (JWT, error) {
 		secret:          []byte(config.Secret),
 		accessValidFor:  time.Minute * 10,
 		refreshValidFor: time.Hour * 24,
+		revoked:         make(map[string]time.Time),
 	}
 
 	if len(j.secret) == 0 {
@@ -132,6 +141,12 @@ func (j *jwt) parseToken(use string) func(c echo.Context, auth string) (interfac
 			return nil, fmt.Errorf("invalid token claim")
 		}
 
+		if jti, ok := token.Claims.(jwtgo.MapClaims)["jti"].(string); ok {
+			if j.isRevoked(jti) {
+				return nil, fmt.Errorf("token has been revoked")
+			}
+		}
+
 		return token, nil
 	}
 }
@@ -258,9 +273,9 @@ func (j *jwt) LoginHandler(c echo.Context) error {
 	})
 }
 
-// RefreshHandler returns a new refresh token
-// @Summary Retrieve a new access token
-// @Description Retrieve a new access token by providing the refresh token
+// RefreshHandler returns a new access token and a new refresh token
+// @Summary Retrieve a new access and refresh token
+// @Description Retrieve a new access token by providing the refresh token. The refresh token is rotated, i.e. the provided refresh token is revoked and a new one is returned.
 // @ID jwt-refresh
 // @Produce json
 // @Success 200 {object} api.JWTRefresh
@@ -278,16 +293,75 @@ func (j *jwt) RefreshHandler(c echo.Context) error {
 		return api.Err(http.StatusForbidden, "", "Invalid subject: %s", err.Error())
 	}
 
-	at, _, err := j.createToken(subject)
+	claims, ok := token.Claims.(jwtgo.MapClaims)
+	if !ok {
+		return api.Err(http.StatusForbidden, "", "Invalid token")
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || len(jti) == 0 {
+		return api.Err(http.StatusForbidden, "", "Invalid token: jti claim is required")
+	}
+
+	expires, err := claims.GetExpirationTime()
+	if err != nil || expires == nil {
+		return api.Err(http.StatusForbidden, "", "Invalid token: exp claim is required")
+	}
+
+	// Revoke the used refresh token. If it has already been revoked, it
+	// has been used concurrently.
+	if !j.revoke(jti, expires.Time) {
+		return api.Err(http.StatusUnauthorized, "", "Missing or invalid JWT token")
+	}
+
+	at, rt, err := j.createToken(subject)
 	if err != nil {
 		return api.Err(http.StatusInternalServerError, "", "Failed to create JWT: %s", err.Error())
 	}
 
 	return c.JSON(http.StatusOK, api.JWTRefresh{
-		AccessToken: at,
+		AccessToken:  at,
+		RefreshToken: rt,
 	})
 }
 
+func (j *jwt) RevokeJWT(jti string) {
+	// The expiry of the token is not known. Keep it as long as a token can be valid.
+	j.revoke(jti, time.Now().Add(j.refreshValidFor))
+}
+
+// revoke adds the jti to the revoked tokens and removes all expired tokens from the
+// list. It returns false if the jti has already been revoked.
+func (j *jwt) revoke(jti string, expires time.Time) bool {
+	j.revokedLock.Lock()
+	defer j.revokedLock.Unlock()
+
+	now := time.Now()
+
+	for id, t := range j.revoked {
+		if t.Before(now) {
+			delete(j.revoked, id)
+		}
+	}
+
+	if _, ok := j.revoked[jti]; ok {
+		return false
+	}
+
+	j.revoked[jti] = expires
+
+	return true
+}
+
+func (j *jwt) isRevoked(jti string) bool {
+	j.revokedLock.Lock()
+	defer j.revokedLock.Unlock()
+
+	_, ok := j.revoked[jti]
+
+	return ok
+}
+
 // Already assigned claims: https://www.iana.org/assignments/jwt/jwt.xhtml
 
 func (j *jwt) createToken(username string) (string, string, error) {
@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/datarhei/core/v16/http/api"
+	"github.com/datarhei/core/v16/http/errorhandler"
+
+	jwtgo "github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T) (*jwt, *echo.Echo) {
+	j, err := New(Config{
+		Realm:  "core",
+		Secret: "secret",
+	})
+	require.NoError(t, err)
+
+	router := echo.New()
+	router.HTTPErrorHandler = errorhandler.HTTPErrorHandler
+	router.GET("/refresh", j.RefreshHandler, j.RefreshMiddleware())
+	router.GET("/access", func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}, j.AccessMiddleware())
+
+	return j.(*jwt), router
+}
+
+func request(router *echo.Echo, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRefreshRotation(t *testing.T) {
+	j, router := newTestRouter(t)
+
+	_, rt, err := j.createToken("foobar")
+	require.NoError(t, err)
+
+	rec := request(router, "/refresh", rt)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	tokens := api.JWTRefresh{}
+	err = json.Unmarshal(rec.Body.Bytes(), &tokens)
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
+	require.NotEqual(t, rt, tokens.RefreshToken)
+
+	rec = request(router, "/access", tokens.AccessToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Reusing the rotated refresh token is rejected
+	rec = request(router, "/refresh", rt)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = request(router, "/refresh", tokens.RefreshToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRevokeJWT(t *testing.T) {
+	j, router := newTestRouter(t)
+
+	at, _, err := j.createToken("foobar")
+	require.NoError(t, err)
+
+	rec := request(router, "/access", at)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	token, _, err := (&jwtgo.Parser{}).ParseUnverified(at, jwtgo.MapClaims{})
+	require.NoError(t, err)
+
+	j.RevokeJWT(token.Claims.(jwtgo.MapClaims)["jti"].(string))
+
+	rec = request(router, "/access", at)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRevokePrune(t *testing.T) {
+	j, _ := newTestRouter(t)
+
+	require.True(t, j.revoke("foo", time.Now().Add(-time.Second)))
+	require.True(t, j.revoke("bar", time.Now().Add(time.Minute)))
+	require.False(t, j.revoke("bar", time.Now().Add(time.Minute)))
+
+	require.False(t, j.isRevoked("foo"))
+	require.True(t, j.isRevoked("bar"))
+}
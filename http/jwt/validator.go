@@ -1,8 +1,11 @@
 package jwt
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/datarhei/core/v16/http/api"
 	"github.com/datarhei/core/v16/http/handler/util"
@@ -57,26 +60,69 @@ func (v *localValidator) Validate(c echo.Context) (bool, string, error) {
 
 func (v *localValidator) Cancel() {}
 
-type auth0Validator struct {
-	domain   string
+// OIDCConfig is the configuration for an OpenID Connect validator
+type OIDCConfig struct {
+	// Issuer is the URL of the issuer. It has to match the "iss" claim of a token.
+	Issuer string
+
+	// Audience is the expected "aud" claim of a token. Optional.
+	Audience string
+
+	// JWKSURL is the URL of the JSON web key set of the issuer. Optional. If it is
+	// not set, it will be discovered from the OpenID configuration of the issuer.
+	JWKSURL string
+
+	// Users is a list of subjects that are allowed to access the API.
+	Users []string
+
+	// Client is the HTTP client for the discovery and for fetching the keys. Optional.
+	Client *http.Client
+}
+
+type oidcValidator struct {
 	issuer   string
 	audience string
-	clientID string
+	jwksURL  string
 	users    []string
 	certs    jwks.JWKS
 }
 
-func NewAuth0Validator(domain, audience, clientID string, users []string) (Validator, error) {
-	v := &auth0Validator{
-		domain:   domain,
-		issuer:   "https://" + domain + "/",
-		audience: audience,
-		clientID: clientID,
-		users:    users,
+// NewOIDCValidator returns a validator for tokens of an OpenID Connect provider, e.g.
+// Keycloak, Okta, or Google. The keys of the issuer are fetched again if a token
+// refers to an unknown key, such that a rotation of the keys is picked up.
+func NewOIDCValidator(config OIDCConfig) (Validator, error) {
+	if len(config.Issuer) == 0 {
+		return nil, fmt.Errorf("no issuer provided")
+	}
+
+	v := &oidcValidator{
+		issuer:   config.Issuer,
+		audience: config.Audience,
+		jwksURL:  config.JWKSURL,
+		users:    config.Users,
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+
+	if len(v.jwksURL) == 0 {
+		url, err := discoverJWKSURL(client, v.issuer)
+		if err != nil {
+			return nil, err
+		}
+
+		v.jwksURL = url
 	}
 
-	url := v.issuer + ".well-known/jwks.json"
-	certs, err := jwks.NewFromURL(url, jwks.Config{})
+	certs, err := jwks.NewFromURL(v.jwksURL, jwks.Config{
+		Client:            client,
+		RefreshRateLimit:  10 * time.Second,
+		RefreshUnknownKID: true,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -86,11 +132,47 @@ func NewAuth0Validator(domain, audience, clientID string, users []string) (Valid
 	return v, nil
 }
 
-func (v auth0Validator) String() string {
-	return fmt.Sprintf("auth0 domain=%s audience=%s clientid=%s", v.domain, v.audience, v.clientID)
+// discoverJWKSURL returns the URL of the JSON web key set from the OpenID
+// configuration of the issuer.
+func discoverJWKSURL(client *http.Client, issuer string) (string, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("discovery of %s failed: %w", issuer, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("discovery of %s failed: unexpected status code %d", issuer, resp.StatusCode)
+	}
+
+	configuration := struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&configuration); err != nil {
+		return "", fmt.Errorf("discovery of %s failed: %w", issuer, err)
+	}
+
+	if strings.TrimSuffix(configuration.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return "", fmt.Errorf("discovery of %s failed: issuer mismatch (%s)", issuer, configuration.Issuer)
+	}
+
+	if len(configuration.JWKSURI) == 0 {
+		return "", fmt.Errorf("discovery of %s failed: no jwks_uri found", issuer)
+	}
+
+	return configuration.JWKSURI, nil
+}
+
+func (v *oidcValidator) String() string {
+	return fmt.Sprintf("oidc issuer=%s audience=%s", v.issuer, v.audience)
 }
 
-func (v *auth0Validator) Validate(c echo.Context) (bool, string, error) {
+func (v *oidcValidator) Validate(c echo.Context) (bool, string, error) {
 	// Look for an Auth header
 	values := c.Request().Header.Values("Authorization")
 	prefix := "Bearer "
@@ -119,7 +201,7 @@ func (v *auth0Validator) Validate(c echo.Context) (bool, string, error) {
 	var issuer string
 	if claims, ok := token.Claims.(jwtgo.MapClaims); ok {
 		if iss, ok := claims["iss"]; ok {
-			issuer = iss.(string)
+			issuer, _ = iss.(string)
 		}
 	}
 
@@ -127,7 +209,15 @@ func (v *auth0Validator) Validate(c echo.Context) (bool, string, error) {
 		return false, "", nil
 	}
 
-	token, err = jwtgo.Parse(auth, v.keyFunc)
+	options := []jwtgo.ParserOption{
+		jwtgo.WithIssuer(v.issuer),
+	}
+
+	if len(v.audience) != 0 {
+		options = append(options, jwtgo.WithAudience(v.audience))
+	}
+
+	token, err = jwtgo.Parse(auth, v.keyFunc, options...)
 	if err != nil {
 		return true, "", err
 	}
@@ -136,17 +226,12 @@ func (v *auth0Validator) Validate(c echo.Context) (bool, string, error) {
 		return true, "", fmt.Errorf("invalid token")
 	}
 
-	var subject string
-	if claims, ok := token.Claims.(jwtgo.MapClaims); ok {
-		if sub, ok := claims["sub"]; ok {
-			subject = sub.(string)
-		}
-	}
+	subject, _ := token.Claims.GetSubject()
 
 	return true, subject, nil
 }
 
-func (v *auth0Validator) keyFunc(token *jwtgo.Token) (interface{}, error) {
+func (v *oidcValidator) keyFunc(token *jwtgo.Token) (interface{}, error) {
 	// Verify 'aud' claim
 	if _, err := token.Claims.GetAudience(); err != nil {
 		return nil, fmt.Errorf("invalid audience: %w", err)
@@ -176,24 +261,22 @@ func (v *auth0Validator) keyFunc(token *jwtgo.Token) (interface{}, error) {
 	}
 
 	// find the key
-	if _, ok := token.Header["kid"]; !ok {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
 		return nil, fmt.Errorf("kid not found")
 	}
 
-	kid := token.Header["kid"].(string)
-
 	key, err := v.certs.Key(kid)
 	if err != nil {
 		return nil, fmt.Errorf("no cert for kid found: %w", err)
 	}
 
 	// find algorithm
-	if _, ok := token.Header["alg"]; !ok {
-		return nil, fmt.Errorf("kid not found")
+	alg, ok := token.Header["alg"].(string)
+	if !ok {
+		return nil, fmt.Errorf("alg not found")
 	}
 
-	alg := token.Header["alg"].(string)
-
 	if key.Alg() != alg {
 		return nil, fmt.Errorf("signing method doesn't match")
 	}
@@ -207,6 +290,41 @@ func (v *auth0Validator) keyFunc(token *jwtgo.Token) (interface{}, error) {
 	return publicKey, nil
 }
 
-func (v *auth0Validator) Cancel() {
+func (v *oidcValidator) Cancel() {
 	v.certs.Cancel()
 }
+
+// auth0Validator is an OpenID Connect validator for an Auth0 tenant
+type auth0Validator struct {
+	Validator
+
+	domain   string
+	audience string
+	clientID string
+}
+
+// NewAuth0Validator returns a validator for tokens of an Auth0 tenant. The keys of
+// the tenant are discovered from its OpenID configuration.
+func NewAuth0Validator(domain, audience, clientID string, users []string) (Validator, error) {
+	oidc, err := NewOIDCValidator(OIDCConfig{
+		Issuer:   "https://" + domain + "/",
+		Audience: audience,
+		Users:    users,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &auth0Validator{
+		Validator: oidc,
+		domain:    domain,
+		audience:  audience,
+		clientID:  clientID,
+	}
+
+	return v, nil
+}
+
+func (v *auth0Validator) String() string {
+	return fmt.Sprintf("auth0 domain=%s audience=%s clientid=%s", v.domain, v.audience, v.clientID)
+}
@@ -0,0 +1,170 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jwtgo "github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProvider struct {
+	server *httptest.Server
+	keys   map[string]*rsa.PrivateKey
+	lock   sync.Mutex
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	p := &mockProvider{
+		keys: map[string]*rsa.PrivateKey{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.server.URL + "/",
+			"jwks_uri": p.server.URL + "/jwks.json",
+		})
+	})
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		keys := []map[string]string{}
+
+		for kid, key := range p.keys {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": keys,
+		})
+	})
+
+	p.server = httptest.NewServer(mux)
+
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *mockProvider) rotate(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.keys = map[string]*rsa.PrivateKey{
+		kid: key,
+	}
+}
+
+func (p *mockProvider) token(t *testing.T, kid, audience, subject string) string {
+	p.lock.Lock()
+	key := p.keys[kid]
+	p.lock.Unlock()
+
+	token := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, jwtgo.MapClaims{
+		"iss": p.server.URL + "/",
+		"aud": audience,
+		"sub": subject,
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validate(v Validator, token string) (bool, string, error) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	return v.Validate(c)
+}
+
+func TestOIDCValidator(t *testing.T) {
+	provider := newMockProvider(t)
+	provider.rotate(t, "key1")
+
+	v, err := NewOIDCValidator(OIDCConfig{
+		Issuer:   provider.server.URL + "/",
+		Audience: "core",
+		Users:    []string{"foobar"},
+	})
+	require.NoError(t, err)
+
+	defer v.Cancel()
+
+	ok, subject, err := validate(v, provider.token(t, "key1", "core", "foobar"))
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", subject)
+
+	ok, _, err = validate(v, provider.token(t, "key1", "other", "foobar"))
+	require.True(t, ok)
+	require.Error(t, err)
+
+	ok, _, err = validate(v, provider.token(t, "key1", "core", "foobaz"))
+	require.True(t, ok)
+	require.Error(t, err)
+
+	// An unknown kid leads to fetching the keys again
+	provider.rotate(t, "key2")
+
+	ok, subject, err = validate(v, provider.token(t, "key2", "core", "foobar"))
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", subject)
+}
+
+func TestOIDCValidatorOtherIssuer(t *testing.T) {
+	provider := newMockProvider(t)
+	provider.rotate(t, "key1")
+
+	v, err := NewOIDCValidator(OIDCConfig{
+		Issuer:  "https://example.com/",
+		JWKSURL: provider.server.URL + "/jwks.json",
+		Users:   []string{"foobar"},
+	})
+	require.NoError(t, err)
+
+	defer v.Cancel()
+
+	ok, _, err := validate(v, provider.token(t, "key1", "core", "foobar"))
+	require.False(t, ok)
+	require.NoError(t, err)
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	provider := newMockProvider(t)
+	provider.rotate(t, "key1")
+
+	_, err := discoverJWKSURL(http.DefaultClient, provider.server.URL+"/realms/foobar/")
+	require.Error(t, err)
+
+	url, err := discoverJWKSURL(http.DefaultClient, provider.server.URL)
+	require.NoError(t, err)
+	require.Equal(t, provider.server.URL+"/jwks.json", url)
+}
@@ -0,0 +1,160 @@
+// Package etag is a middleware that adds an ETag to responses and answers
+// conditional requests with "304 Not Modified"
+package etag
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type Config struct {
+	Skipper middleware.Skipper
+}
+
+var DefaultConfig = Config{
+	Skipper: middleware.DefaultSkipper,
+}
+
+func New() echo.MiddlewareFunc {
+	return NewWithConfig(DefaultConfig)
+}
+
+// NewWithConfig returns a middleware that buffers the response of GET and HEAD
+// requests and sets a weak ETag based on the body, unless the handler already set
+// an ETag. If the If-None-Match header of the request matches the ETag, or the
+// If-Modified-Since header is not before the Last-Modified header the handler set,
+// the body will be discarded and "304 Not Modified" will be returned.
+func NewWithConfig(config Config) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			res := c.Response()
+
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(c)
+			}
+
+			writer := res.Writer
+
+			w := &etagWriter{
+				header: writer.Header(),
+				code:   http.StatusOK,
+			}
+			res.Writer = w
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// Restore original writer and write the response through echo, such
+			// that the status and size of the response are recorded correctly.
+			res.Writer = writer
+			res.Committed = false
+			res.Size = 0
+
+			if w.code != http.StatusOK {
+				res.WriteHeader(w.code)
+				res.Write(w.body.Bytes())
+
+				return nil
+			}
+
+			etag := w.header.Get("ETag")
+			if len(etag) == 0 {
+				sum := sha1.Sum(w.body.Bytes())
+				etag = `W/"` + hex.EncodeToString(sum[:]) + `"`
+				w.header.Set("ETag", etag)
+			}
+
+			if isNotModified(req.Header, w.header) {
+				w.header.Del(echo.HeaderContentType)
+				w.header.Del(echo.HeaderContentLength)
+				w.header.Del(echo.HeaderContentEncoding)
+
+				res.WriteHeader(http.StatusNotModified)
+
+				return nil
+			}
+
+			res.WriteHeader(w.code)
+			res.Write(w.body.Bytes())
+
+			return nil
+		}
+	}
+}
+
+// isNotModified checks the conditional headers of a request against the ETag and
+// Last-Modified headers of the response. If-None-Match takes precedence over
+// If-Modified-Since, see RFC 7232, section 6.
+func isNotModified(request, response http.Header) bool {
+	if match := request.Get("If-None-Match"); len(match) != 0 {
+		etag := trimWeak(response.Get("ETag"))
+
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || trimWeak(tag) == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since := request.Get("If-Modified-Since")
+	lastModified := response.Get("Last-Modified")
+
+	if len(since) == 0 || len(lastModified) == 0 {
+		return false
+	}
+
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+
+	lastModifiedTime, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !lastModifiedTime.After(sinceTime)
+}
+
+// trimWeak removes the weakness indicator from an ETag for a weak comparison.
+func trimWeak(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+type etagWriter struct {
+	code   int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *etagWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+func (w *etagWriter) Write(body []byte) (int, error) {
+	return w.body.Write(body)
+}
@@ -0,0 +1,110 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	e := echo.New()
+
+	handler := New()(func(c echo.Context) error {
+		return c.String(http.StatusOK, "foobar")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "foobar", rec.Body.String())
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+
+	require.NoError(t, handler(c))
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Equal(t, "", rec.Body.String())
+	require.Equal(t, etag, rec.Header().Get("ETag"))
+
+	// The status and size are recorded for the access log and the metrics
+	require.Equal(t, http.StatusNotModified, c.Response().Status)
+	require.Equal(t, int64(0), c.Response().Size)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"foo", W/"bar"`)
+	rec = httptest.NewRecorder()
+
+	c = e.NewContext(req, rec)
+
+	require.NoError(t, handler(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "foobar", rec.Body.String())
+	require.Equal(t, http.StatusOK, c.Response().Status)
+	require.Equal(t, int64(6), c.Response().Size)
+}
+
+func TestLastModified(t *testing.T) {
+	e := echo.New()
+
+	handler := New()(func(c echo.Context) error {
+		c.Response().Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		return c.String(http.StatusOK, "foobar")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", "Wed, 21 Oct 2015 07:28:00 GMT")
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusNotModified, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", "Tue, 20 Oct 2015 07:28:00 GMT")
+	rec = httptest.NewRecorder()
+
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "foobar", rec.Body.String())
+}
+
+func TestNotOK(t *testing.T) {
+	e := echo.New()
+
+	handler := New()(func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Empty(t, rec.Header().Get("ETag"))
+}
+
+func TestSkipMethod(t *testing.T) {
+	e := echo.New()
+
+	handler := New()(func(c echo.Context) error {
+		return c.String(http.StatusOK, "foobar")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("ETag"))
+}
@@ -0,0 +1,125 @@
+package gzip
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// DecompressConfig defines the config for the Decompress middleware.
+type DecompressConfig struct {
+	// Skipper defines a function to skip middleware.
+	Skipper middleware.Skipper
+
+	// Max. size of the decompressed request body in bytes. Reading
+	// more data fails. Optional. Default value 0 for no limit.
+	MaxSize int64
+}
+
+// DefaultDecompressConfig is the default Decompress middleware config.
+var DefaultDecompressConfig = DecompressConfig{
+	Skipper: middleware.DefaultSkipper,
+	MaxSize: 0,
+}
+
+type decompressReader struct {
+	reader  *gzip.Reader
+	body    io.ReadCloser
+	maxSize int64
+	read    int64
+}
+
+// NewDecompress returns a middleware which decompresses gzip compressed
+// request bodies.
+func NewDecompress() echo.MiddlewareFunc {
+	return NewDecompressWithConfig(DefaultDecompressConfig)
+}
+
+// NewDecompressWithConfig returns a Decompress middleware with config.
+// See: `NewDecompress()`.
+func NewDecompressWithConfig(config DecompressConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultDecompressConfig.Skipper
+	}
+
+	if config.MaxSize < 0 {
+		config.MaxSize = DefaultDecompressConfig.MaxSize
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			if !strings.EqualFold(strings.TrimSpace(req.Header.Get(echo.HeaderContentEncoding)), gzipScheme) {
+				return next(c)
+			}
+
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			gr, err := gzip.NewReader(req.Body)
+			if err != nil {
+				if err == io.EOF {
+					return next(c)
+				}
+
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid gzip encoded body")
+			}
+
+			r := &decompressReader{
+				reader:  gr,
+				body:    req.Body,
+				maxSize: config.MaxSize,
+			}
+
+			defer r.Close()
+
+			// The handler reads the plain body, the length of it is not known
+			req.Body = r
+			req.ContentLength = -1
+			req.Header.Del(echo.HeaderContentEncoding)
+			req.Header.Del(echo.HeaderContentLength)
+
+			return next(c)
+		}
+	}
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.maxSize > 0 {
+		if r.read >= r.maxSize {
+			// Check whether there's more data than allowed
+			var b [1]byte
+			if n, _ := r.reader.Read(b[:]); n != 0 {
+				return 0, echo.ErrStatusRequestEntityTooLarge
+			}
+
+			return 0, io.EOF
+		}
+
+		if int64(len(p)) > r.maxSize-r.read {
+			p = p[:r.maxSize-r.read]
+		}
+	}
+
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	return n, err
+}
+
+func (r *decompressReader) Close() error {
+	r.reader.Close()
+
+	return r.body.Close()
+}
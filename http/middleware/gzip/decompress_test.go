@@ -0,0 +1,134 @@
+package gzip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipData(data string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(data))
+	w.Close()
+
+	return buf
+}
+
+func TestDecompress(t *testing.T) {
+	e := echo.New()
+
+	var body string
+	var encoding string
+
+	h := NewDecompress()(func(c echo.Context) error {
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		body = string(data)
+		encoding = c.Request().Header.Get(echo.HeaderContentEncoding)
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	assert := assert.New(t)
+
+	// Identity
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("test"))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(h(c))
+	assert.Equal("test", body)
+
+	// Gzip
+	req = httptest.NewRequest(http.MethodPost, "/", gzipData("test"))
+	req.Header.Set(echo.HeaderContentEncoding, gzipScheme)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	assert.NoError(h(c))
+	assert.Equal("test", body)
+	assert.Equal("", encoding)
+	assert.Equal(int64(-1), req.ContentLength)
+
+	// Empty body
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(echo.HeaderContentEncoding, gzipScheme)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	assert.NoError(h(c))
+	assert.Equal("", body)
+
+	// Invalid gzip
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("test"))
+	req.Header.Set(echo.HeaderContentEncoding, gzipScheme)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	err := h(c)
+	if assert.Error(err) {
+		he, ok := err.(*echo.HTTPError)
+		if assert.True(ok) {
+			assert.Equal(http.StatusBadRequest, he.Code)
+		}
+	}
+}
+
+func TestDecompressMaxSize(t *testing.T) {
+	e := echo.New()
+
+	var body string
+
+	h := NewDecompressWithConfig(DecompressConfig{
+		MaxSize: 10,
+	})(func(c echo.Context) error {
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		body = string(data)
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	assert := assert.New(t)
+
+	// Exactly the max. size
+	req := httptest.NewRequest(http.MethodPost, "/", gzipData("0123456789"))
+	req.Header.Set(echo.HeaderContentEncoding, gzipScheme)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(h(c))
+	assert.Equal("0123456789", body)
+
+	// Highly compressible data exceeding the max. size
+	req = httptest.NewRequest(http.MethodPost, "/", gzipData(strings.Repeat("0", 1024*1024)))
+	req.Header.Set(echo.HeaderContentEncoding, gzipScheme)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	err := h(c)
+	assert.ErrorIs(err, echo.ErrStatusRequestEntityTooLarge)
+
+	// The limit doesn't apply to uncompressed bodies
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("01234567890123456789"))
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	assert.NoError(h(c))
+	assert.Equal("01234567890123456789", body)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 
@@ -26,6 +27,29 @@ type Config struct {
 	// Length threshold before gzip compression
 	// is used. Optional. Default value 0
 	MinLength int
+
+	// List of content types of responses that should be
+	// compressed, in addition to the Skipper. The content type
+	// has to be set before the middleware is called. Optional.
+	// Default value nil for all content types.
+	ContentTypes []string
+}
+
+// GroupConfig defines how the config of the Gzip middleware is
+// changed for a group of routes.
+type GroupConfig struct {
+	// List of content types that replaces the list of content
+	// types of the config. A non-nil empty list compresses all
+	// content types. Optional. Default value nil for no change.
+	ContentTypes []string
+
+	// Add ContentTypes to the list of content types of the config
+	// instead of replacing it. Optional. Default value false.
+	MergeContentTypes bool
+
+	// Length threshold that replaces the threshold of the config.
+	// Optional. Default value nil for no change.
+	MinLength *int
 }
 
 type gzipResponseWriter struct {
@@ -78,6 +102,33 @@ func ContentTypeSkipper(contentTypes []string) middleware.Skipper {
 	}
 }
 
+// NewGroupWithConfig returns a Gzip middleware with the config as changed by the group
+// config. Use it for groups of routes that need a different compression policy than the
+// rest of the routes.
+func NewGroupWithConfig(config Config, group GroupConfig) echo.MiddlewareFunc {
+	if group.ContentTypes != nil {
+		contentTypes := []string{}
+
+		if group.MergeContentTypes {
+			contentTypes = append(contentTypes, config.ContentTypes...)
+		}
+
+		for _, contentType := range group.ContentTypes {
+			if !slices.Contains(contentTypes, contentType) {
+				contentTypes = append(contentTypes, contentType)
+			}
+		}
+
+		config.ContentTypes = contentTypes
+	}
+
+	if group.MinLength != nil {
+		config.MinLength = *group.MinLength
+	}
+
+	return NewWithConfig(config)
+}
+
 // New returns a middleware which compresses HTTP response using gzip compression
 // scheme.
 func New() echo.MiddlewareFunc {
@@ -100,12 +151,14 @@ func NewWithConfig(config Config) echo.MiddlewareFunc {
 		config.MinLength = DefaultConfig.MinLength
 	}
 
+	contentTypeSkipper := ContentTypeSkipper(config.ContentTypes)
+
 	pool := gzipPool(config)
 	bpool := bufferPool()
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			if config.Skipper(c) {
+			if config.Skipper(c) || contentTypeSkipper(c) {
 				return next(c)
 			}
 
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -217,6 +218,109 @@ func TestGzipWithStatic(t *testing.T) {
 	}
 }
 
+func TestGzipWithContentTypes(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := NewWithConfig(Config{ContentTypes: []string{"application/json"}})(func(c echo.Context) error {
+		c.Response().Write([]byte("test"))
+		return nil
+	})
+
+	// The content type is not known before the handler is called
+	h(c)
+	assert.Equal(t, "", rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, "test", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	h(c)
+	assert.Equal(t, gzipScheme, rec.Header().Get(echo.HeaderContentEncoding))
+}
+
+func TestGzipGroups(t *testing.T) {
+	contentTypes := map[string]string{
+		".json": echo.MIMEApplicationJSON,
+		".txt":  echo.MIMETextPlain,
+		".m3u8": "application/x-mpegurl",
+		".ts":   "video/mp2t",
+	}
+
+	e := echo.New()
+
+	// Set the content type in advance, like the mime middleware does
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderContentType, contentTypes[path.Ext(c.Request().URL.Path)])
+			return next(c)
+		}
+	})
+
+	config := Config{
+		MinLength:    10,
+		ContentTypes: []string{echo.MIMEApplicationJSON, echo.MIMETextPlain},
+	}
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Param("*"))
+	}
+
+	api := e.Group("/api", NewGroupWithConfig(config, GroupConfig{
+		ContentTypes: []string{echo.MIMEApplicationJSON},
+	}))
+	api.GET("/*", handler)
+
+	minLength := 0
+	memfs := e.Group("/memfs", NewGroupWithConfig(config, GroupConfig{
+		ContentTypes:      []string{"application/x-mpegurl"},
+		MergeContentTypes: true,
+		MinLength:         &minLength,
+	}))
+	memfs.GET("/*", handler)
+
+	tests := map[string]bool{
+		"/api/processes.json":    true,
+		"/api/log.txt":           false,
+		"/api/a.json":            false,
+		"/memfs/a.json":          true,
+		"/memfs/a.txt":           true,
+		"/memfs/playlist.m3u8":   true,
+		"/memfs/segment_0001.ts": false,
+	}
+
+	for p, compressed := range tests {
+		req := httptest.NewRequest(http.MethodGet, p, nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, p)
+
+		if !compressed {
+			assert.Equal(t, "", rec.Header().Get(echo.HeaderContentEncoding), p)
+			assert.Equal(t, path.Base(p), rec.Body.String(), p)
+			continue
+		}
+
+		assert.Equal(t, gzipScheme, rec.Header().Get(echo.HeaderContentEncoding), p)
+
+		r, err := gzip.NewReader(rec.Body)
+		if assert.NoError(t, err, p) {
+			buf := new(bytes.Buffer)
+			buf.ReadFrom(r)
+			r.Close()
+			assert.Equal(t, path.Base(p), buf.String(), p)
+		}
+	}
+}
+
 func BenchmarkGzip(b *testing.B) {
 	e := echo.New()
 
@@ -0,0 +1,201 @@
+package session
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/url"
+	urlpath "path"
+	"regexp"
+	"strings"
+)
+
+// dashURIAttributes are the attributes of the elements in a DASH manifest that
+// refer to a segment.
+var dashURIAttributes = map[string][]string{
+	"SegmentTemplate":     {"media", "initialization", "index"},
+	"SegmentURL":          {"media", "index"},
+	"Initialization":      {"sourceURL"},
+	"RepresentationIndex": {"sourceURL"},
+}
+
+// reXMLAttribute matches an attribute with its value in a start element
+var reXMLAttribute = regexp.MustCompile(`([A-Za-z_:][-A-Za-z0-9_.:]*)\s*=\s*("[^"]*"|'[^']*')`)
+
+// rewriteDASH adds the session ID to all URLs in a DASH manifest that refer to a
+// segment, i.e. the attributes of segment templates and segment URLs, and the
+// base URLs. The manifest is parsed as XML and only the affected parts are
+// replaced, everything else is written unmodified. If the manifest is not valid
+// XML, it is left untouched.
+//
+// The session ID is also added to the location of the manifest, such that a player
+// keeps the session when it refreshes the manifest. If the manifest has no location,
+// the location is set to the requested URL.
+func (g *sessionRewriter) rewriteDASH(sessionID string, requrl *url.URL) {
+	data := g.buffer.Bytes()
+
+	var buffer bytes.Buffer
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	start := int64(0)
+	last := int64(0)
+	inBaseURL := false
+	inLocation := false
+	hasLocation := false
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return
+		}
+
+		end := decoder.InputOffset()
+		raw := data[start:end]
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "BaseURL" {
+				inBaseURL = true
+				break
+			}
+
+			if t.Name.Local == "Location" {
+				inLocation = true
+				hasLocation = true
+				break
+			}
+
+			// The location precedes the periods
+			if t.Name.Local == "Period" && !hasLocation {
+				q := requrl.Query()
+				q.Set("session", sessionID)
+
+				buffer.Write(data[last:start])
+				buffer.WriteString("<Location>")
+				xml.EscapeText(&buffer, []byte(urlpath.Base(requrl.Path)+"?"+q.Encode()))
+				buffer.WriteString("</Location>")
+				last = start
+				hasLocation = true
+				break
+			}
+
+			attributes, ok := dashURIAttributes[t.Name.Local]
+			if !ok {
+				break
+			}
+
+			buffer.Write(data[last:start])
+			buffer.Write(rewriteDASHAttributes(raw, t, attributes, sessionID))
+			last = end
+		case xml.EndElement:
+			if t.Name.Local == "BaseURL" {
+				inBaseURL = false
+			} else if t.Name.Local == "Location" {
+				inLocation = false
+			}
+		case xml.CharData:
+			if inLocation {
+				u, err := url.Parse(strings.TrimSpace(string(t)))
+				if err != nil {
+					break
+				}
+
+				q := u.Query()
+				q.Set("session", sessionID)
+				u.RawQuery = q.Encode()
+
+				buffer.Write(data[last:start])
+				xml.EscapeText(&buffer, []byte(u.String()))
+				last = end
+				break
+			}
+
+			if !inBaseURL {
+				break
+			}
+
+			uri, ok := appendSessionID(strings.TrimSpace(string(t)), sessionID)
+			if !ok {
+				break
+			}
+
+			buffer.Write(data[last:start])
+			xml.EscapeText(&buffer, []byte(uri))
+			last = end
+		}
+
+		start = end
+	}
+
+	buffer.Write(data[last:])
+
+	g.buffer = buffer
+}
+
+// rewriteDASHAttributes adds the session ID to the given attributes of the raw start element.
+func rewriteDASHAttributes(raw []byte, element xml.StartElement, attributes []string, sessionID string) []byte {
+	values := map[string]string{}
+
+	for _, attr := range element.Attr {
+		name := attr.Name.Local
+		if len(attr.Name.Space) != 0 {
+			name = attr.Name.Space + ":" + name
+		}
+
+		values[name] = attr.Value
+	}
+
+	return reXMLAttribute.ReplaceAllFunc(raw, func(match []byte) []byte {
+		name := string(reXMLAttribute.FindSubmatch(match)[1])
+
+		found := false
+		for _, a := range attributes {
+			if a == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return match
+		}
+
+		uri, ok := appendSessionID(values[name], sessionID)
+		if !ok {
+			return match
+		}
+
+		var value bytes.Buffer
+
+		value.WriteString(name + `="`)
+		xml.EscapeText(&value, []byte(uri))
+		value.WriteString(`"`)
+
+		return value.Bytes()
+	})
+}
+
+// appendSessionID adds the session ID to the query string of a relative URL
+// that refers to a segment. The URL is not parsed as such, because it may
+// contain template identifiers like $Number%05d$ which are not valid in an URL.
+func appendSessionID(uri, sessionID string) (string, bool) {
+	if len(uri) == 0 || strings.Contains(uri, "://") {
+		return uri, false
+	}
+
+	path, query, hasQuery := strings.Cut(uri, "?")
+	if !isSegment(path) {
+		return uri, false
+	}
+
+	if hasQuery && len(query) != 0 {
+		return uri + "&session=" + sessionID, true
+	}
+
+	return path + "?session=" + sessionID, true
+}
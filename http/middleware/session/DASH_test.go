@@ -0,0 +1,197 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/datarhei/core/v16/session"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+var mpd = `<?xml version="1.0" encoding="utf-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="dynamic" minimumUpdatePeriod="PT2S">
+	<!-- media="comment.m4s" -->
+	<Period id="0" start="PT0.0S">
+		<AdaptationSet id="0" contentType="video" mimeType="video/mp4">
+			<Representation id="0" bandwidth="1000000" codecs="avc1.64001f">
+				<SegmentTemplate timescale="1000" initialization="init-stream$RepresentationID$.m4s" media='chunk-stream$RepresentationID$-$Number%05d$.m4s' startNumber="1"/>
+			</Representation>
+		</AdaptationSet>
+		<AdaptationSet id="1" contentType="audio" mimeType="audio/mp4">
+			<BaseURL>https://example.com/audio/</BaseURL>
+			<Representation id="1" bandwidth="128000">
+				<SegmentTemplate initialization="init.m4s?foo=bar" media="https://example.com/audio/$Number$.m4s"/>
+			</Representation>
+		</AdaptationSet>
+		<AdaptationSet id="2" contentType="text" mimeType="application/mp4">
+			<Representation id="2" bandwidth="1000">
+				<BaseURL> subtitles.mp4 </BaseURL>
+			</Representation>
+		</AdaptationSet>
+	</Period>
+</MPD>
+`
+
+func TestRewriteDASH(t *testing.T) {
+	rewriter := &sessionRewriter{}
+	rewriter.buffer.WriteString(mpd)
+
+	rewriter.rewriteDASH("abc", &url.URL{Path: "/memfs/foobar.mpd"})
+
+	require.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="dynamic" minimumUpdatePeriod="PT2S">
+	<!-- media="comment.m4s" -->
+	<Location>foobar.mpd?session=abc</Location><Period id="0" start="PT0.0S">
+		<AdaptationSet id="0" contentType="video" mimeType="video/mp4">
+			<Representation id="0" bandwidth="1000000" codecs="avc1.64001f">
+				<SegmentTemplate timescale="1000" initialization="init-stream$RepresentationID$.m4s?session=abc" media="chunk-stream$RepresentationID$-$Number%05d$.m4s?session=abc" startNumber="1"/>
+			</Representation>
+		</AdaptationSet>
+		<AdaptationSet id="1" contentType="audio" mimeType="audio/mp4">
+			<BaseURL>https://example.com/audio/</BaseURL>
+			<Representation id="1" bandwidth="128000">
+				<SegmentTemplate initialization="init.m4s?foo=bar&amp;session=abc" media="https://example.com/audio/$Number$.m4s"/>
+			</Representation>
+		</AdaptationSet>
+		<AdaptationSet id="2" contentType="text" mimeType="application/mp4">
+			<Representation id="2" bandwidth="1000">
+				<BaseURL>subtitles.mp4?session=abc</BaseURL>
+			</Representation>
+		</AdaptationSet>
+	</Period>
+</MPD>
+`, rewriter.buffer.String())
+}
+
+func TestRewriteDASHInvalid(t *testing.T) {
+	data := `<MPD><SegmentTemplate media="chunk.m4s"></MPD`
+
+	rewriter := &sessionRewriter{}
+	rewriter.buffer.WriteString(data)
+
+	rewriter.rewriteDASH("abc", &url.URL{Path: "/memfs/foobar.mpd"})
+
+	require.Equal(t, data, rewriter.buffer.String())
+}
+
+func TestRewriteDASHLocation(t *testing.T) {
+	data := `<MPD>
+	<Location>https://example.com/live/foobar.mpd?token=123&amp;session=xyz</Location>
+	<Period><SegmentTemplate media="chunk.m4s"/></Period>
+	<Period><SegmentTemplate media="chunk.m4s"/></Period>
+</MPD>`
+
+	rewriter := &sessionRewriter{}
+	rewriter.buffer.WriteString(data)
+
+	rewriter.rewriteDASH("abc", &url.URL{Path: "/memfs/foobar.mpd"})
+
+	require.Equal(t, `<MPD>
+	<Location>https://example.com/live/foobar.mpd?session=abc&amp;token=123</Location>
+	<Period><SegmentTemplate media="chunk.m4s?session=abc"/></Period>
+	<Period><SegmentTemplate media="chunk.m4s?session=abc"/></Period>
+</MPD>`, rewriter.buffer.String())
+}
+
+func TestDASHEgress(t *testing.T) {
+	collector := session.NewCollector(session.CollectorConfig{
+		SessionTimeout: time.Minute,
+	})
+	defer collector.Stop()
+
+	router := echo.New()
+	router.Use(NewHLSWithConfig(HLSConfig{
+		EgressCollector:  collector,
+		IngressCollector: session.NewNullCollector(),
+	}))
+	router.GET("/memfs/foobar.mpd", func(c echo.Context) error {
+		return c.String(http.StatusOK, mpd)
+	})
+	router.GET("/memfs/chunk-stream0-00001.m4s", func(c echo.Context) error {
+		return c.String(http.StatusOK, "segment")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memfs/foobar.mpd", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	match := regexp.MustCompile(`\.m4s\?session=([^"]+)"`).FindStringSubmatch(rec.Body.String())
+	require.NotNil(t, match)
+
+	sessionID := match[1]
+	require.True(t, collector.IsKnownSession(sessionID))
+	require.Equal(t, uint64(0), collector.Sessions())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memfs/chunk-stream0-00001.m4s?session="+sessionID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Equal(t, uint64(1), collector.Sessions())
+
+	active := collector.Active()
+	require.Equal(t, 1, len(active))
+	require.Equal(t, sessionID, active[0].ID)
+	require.Greater(t, active[0].TxBytes, uint64(len(mpd)))
+}
+
+func TestDASHRefresh(t *testing.T) {
+	collector := session.NewCollector(session.CollectorConfig{
+		SessionTimeout: time.Minute,
+	})
+	defer collector.Stop()
+
+	router := echo.New()
+	router.Use(NewHLSWithConfig(HLSConfig{
+		EgressCollector:  collector,
+		IngressCollector: session.NewNullCollector(),
+	}))
+	router.GET("/memfs/foobar.mpd", func(c echo.Context) error {
+		return c.String(http.StatusOK, mpd)
+	})
+	router.GET("/memfs/chunk-stream0-00001.m4s", func(c echo.Context) error {
+		return c.String(http.StatusOK, "segment")
+	})
+
+	reLocation := regexp.MustCompile(`<Location>([^<]+)</Location>`)
+	reSession := regexp.MustCompile(`\.m4s\?session=([^"]+)"`)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memfs/foobar.mpd", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	match := reSession.FindStringSubmatch(rec.Body.String())
+	require.NotNil(t, match)
+
+	sessionID := match[1]
+
+	location := reLocation.FindStringSubmatch(rec.Body.String())
+	require.NotNil(t, location)
+	require.Equal(t, "foobar.mpd?session="+sessionID, location[1])
+
+	// The player refreshes the manifest from its location
+	for i := 0; i < 3; i++ {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memfs/"+location[1], nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		match = reSession.FindStringSubmatch(rec.Body.String())
+		require.NotNil(t, match)
+		require.Equal(t, sessionID, match[1])
+
+		match = reLocation.FindStringSubmatch(rec.Body.String())
+		require.NotNil(t, match)
+		require.Equal(t, location[1], match[1])
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memfs/chunk-stream0-00001.m4s?session="+sessionID, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	require.Equal(t, uint64(1), collector.Sessions())
+}
@@ -4,6 +4,7 @@ package session
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/datarhei/core/v16/net"
 	"github.com/datarhei/core/v16/session"
@@ -26,14 +28,29 @@ type HLSConfig struct {
 	Skipper          middleware.Skipper
 	EgressCollector  session.Collector
 	IngressCollector session.Collector
+
+	// SessionIDPattern is a regular expression the session ID of a request
+	// has to match. Requests with a non-matching session ID are rejected.
+	// Short UUIDs, which the middleware generates for new sessions, are
+	// always accepted. Optional. Default value are short UUIDs.
+	SessionIDPattern string
+
+	// IdleTimeout is the duration after which an egress session without any
+	// segment requests is closed, even if the client still requests the playlist.
+	// Closing the session frees its slot for new sessions. Set to 0 to disable.
+	IdleTimeout time.Duration
 }
 
 var DefaultHLSConfig = HLSConfig{
 	Skipper:          middleware.DefaultSkipper,
 	EgressCollector:  session.NewNullCollector(),
 	IngressCollector: session.NewNullCollector(),
+	SessionIDPattern: `^[` + regexp.QuoteMeta(shortuuid.DefaultAlphabet) + `]{22}$`,
 }
 
+// reShortUUID matches the session IDs the middleware generates itself.
+var reShortUUID = regexp.MustCompile(DefaultHLSConfig.SessionIDPattern)
+
 // NewHTTP returns a new HTTP session middleware with default config
 func NewHLS() echo.MiddlewareFunc {
 	return NewHLSWithConfig(DefaultHLSConfig)
@@ -46,10 +63,26 @@ type hls struct {
 
 	rxsegments map[string]int64
 	lock       sync.Mutex
+
+	idleTimeout time.Duration
+	activity    map[string]time.Time
+	lastSweep   time.Time
+	idleLock    sync.Mutex
 }
 
-// NewHLS returns a new HLS session middleware
+// NewHLS returns a new HLS session middleware. It panics if the config is invalid.
 func NewHLSWithConfig(config HLSConfig) echo.MiddlewareFunc {
+	mw, err := config.ToMiddleware()
+	if err != nil {
+		panic(err)
+	}
+
+	return mw
+}
+
+// ToMiddleware converts the config to a HLS session middleware. It returns
+// an error if the config is invalid.
+func (config HLSConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 	if config.Skipper == nil {
 		config.Skipper = DefaultHLSConfig.Skipper
 	}
@@ -66,11 +99,22 @@ func NewHLSWithConfig(config HLSConfig) echo.MiddlewareFunc {
 		config.IngressCollector = DefaultHLSConfig.IngressCollector
 	}
 
+	if len(config.SessionIDPattern) == 0 {
+		config.SessionIDPattern = DefaultHLSConfig.SessionIDPattern
+	}
+
+	reSessionID, err := regexp.Compile(config.SessionIDPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID pattern: %w", err)
+	}
+
 	hls := hls{
 		egressCollector:  config.EgressCollector,
 		ingressCollector: config.IngressCollector,
-		reSessionID:      regexp.MustCompile(`^[` + regexp.QuoteMeta(shortuuid.DefaultAlphabet) + `]{22}$`),
+		reSessionID:      reSessionID,
 		rxsegments:       make(map[string]int64),
+		idleTimeout:      config.IdleTimeout,
+		activity:         make(map[string]time.Time),
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -89,7 +133,7 @@ func NewHLSWithConfig(config HLSConfig) echo.MiddlewareFunc {
 
 			return next(c)
 		}
-	}
+	}, nil
 }
 
 func (h *hls) handleIngress(c echo.Context, next echo.HandlerFunc) error {
@@ -137,8 +181,8 @@ func (h *hls) handleIngress(c echo.Context, next echo.HandlerFunc) error {
 				h.lock.Unlock()
 			}
 		}()
-	} else if strings.HasSuffix(path, ".ts") {
-		// Get the size of the .ts file and store it in the ts-map for later use.
+	} else if isSegment(path) {
+		// Get the size of the segment file and store it in the segment-map for later use.
 		reader := req.Body
 		r := &bodysizeReader{
 			reader: req.Body,
@@ -171,11 +215,14 @@ func (h *hls) handleEgress(c echo.Context, next echo.HandlerFunc) error {
 	sessionID := c.QueryParam("session")
 
 	isM3U8 := strings.HasSuffix(path, ".m3u8")
-	isTS := strings.HasSuffix(path, ".ts")
+	isMPD := strings.HasSuffix(path, ".mpd")
+	isTS := isSegment(path)
 
 	rewrite := false
 
-	if isM3U8 {
+	h.sweep(time.Now())
+
+	if isM3U8 || isMPD {
 		if !h.egressCollector.IsKnownSession(sessionID) {
 			if h.egressCollector.IsSessionsExceeded() {
 				return echo.NewHTTPError(509, "Number of sessions exceeded")
@@ -195,8 +242,15 @@ func (h *hls) handleEgress(c echo.Context, next echo.HandlerFunc) error {
 				}
 			}
 
+			// A DASH manifest doesn't reference other manifests that could carry a session ID,
+			// therefore a new session is created right away. The rewritten manifest refers to
+			// itself with the session ID, such that refreshes belong to the same session.
+			if isMPD && len(sessionID) == 0 {
+				sessionID = shortuuid.New()
+			}
+
 			if len(sessionID) != 0 {
-				if !h.reSessionID.MatchString(sessionID) {
+				if !h.isValidSessionID(sessionID) {
 					return echo.NewHTTPError(http.StatusForbidden)
 				}
 
@@ -216,6 +270,8 @@ func (h *hls) handleEgress(c echo.Context, next echo.HandlerFunc) error {
 
 				// Give the new session an initial top bitrate
 				h.egressCollector.SessionSetTopEgressBitrate(sessionID, streamBitrate)
+
+				h.touch(sessionID, time.Now())
 			}
 		}
 
@@ -251,13 +307,17 @@ func (h *hls) handleEgress(c echo.Context, next echo.HandlerFunc) error {
 		}
 
 		// Rewrite the data befor sending it to the client
-		rewriter.rewriteHLS(sessionID, c.Request().URL)
+		if isMPD {
+			rewriter.rewriteDASH(sessionID, c.Request().URL)
+		} else {
+			rewriter.rewriteHLS(sessionID, c.Request().URL)
+		}
 
 		res.Header().Set("Cache-Control", "private")
 		res.Write(rewriter.buffer.Bytes())
 	}
 
-	if isM3U8 || isTS {
+	if isM3U8 || isMPD || isTS {
 		// Collect how many bytes we've written in this session
 		h.egressCollector.Egress(sessionID, headerSize(res.Header()))
 		h.egressCollector.Egress(sessionID, res.Size)
@@ -265,12 +325,140 @@ func (h *hls) handleEgress(c echo.Context, next echo.HandlerFunc) error {
 		if isTS {
 			// Activate the session. If the session is already active, this is a noop
 			h.egressCollector.Activate(sessionID)
+
+			h.touch(sessionID, time.Now())
 		}
 	}
 
 	return nil
 }
 
+// isValidSessionID returns whether the session ID has been generated by the
+// middleware or matches the configured pattern.
+func (h *hls) isValidSessionID(sessionID string) bool {
+	return reShortUUID.MatchString(sessionID) || h.reSessionID.MatchString(sessionID)
+}
+
+// touch records segment activity for an egress session.
+func (h *hls) touch(sessionID string, now time.Time) {
+	if h.idleTimeout <= 0 || len(sessionID) == 0 {
+		return
+	}
+
+	h.idleLock.Lock()
+	defer h.idleLock.Unlock()
+
+	if _, ok := h.activity[sessionID]; !ok && !h.egressCollector.IsKnownSession(sessionID) {
+		return
+	}
+
+	h.activity[sessionID] = now
+}
+
+// sweep closes all egress sessions without segment activity for longer than the
+// idle timeout. Sessions that have already been closed by the collector are
+// forgotten. In order to not walk all sessions with every request, a sweep is
+// done at most once per second.
+func (h *hls) sweep(now time.Time) {
+	if h.idleTimeout <= 0 {
+		return
+	}
+
+	interval := time.Second
+	if h.idleTimeout < interval {
+		interval = h.idleTimeout
+	}
+
+	h.idleLock.Lock()
+	defer h.idleLock.Unlock()
+
+	if now.Sub(h.lastSweep) < interval {
+		return
+	}
+
+	h.lastSweep = now
+
+	for sessionID, last := range h.activity {
+		if !h.egressCollector.IsKnownSession(sessionID) {
+			delete(h.activity, sessionID)
+			continue
+		}
+
+		if now.Sub(last) < h.idleTimeout {
+			continue
+		}
+
+		h.egressCollector.Unregister(sessionID)
+		delete(h.activity, sessionID)
+	}
+}
+
+// segmentExtensions are the file extensions of media segments. Besides MPEG-TS,
+// these are the fragmented MP4 (CMAF) segments and their init segment.
+var segmentExtensions = []string{".ts", ".m4s", ".mp4"}
+
+// isSegment returns whether the path refers to a media segment
+func isSegment(path string) bool {
+	for _, ext := range segmentExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// uriTags are the tags in a manifest that refer to a segment with an URI attribute. Besides
+// the init segment, these are the partial segments of low-latency HLS.
+var uriTags = []string{"#EXT-X-MAP:", "#EXT-X-PART:", "#EXT-X-PRELOAD-HINT:"}
+
+// reTagURI matches the URI attribute of a tag
+var reTagURI = regexp.MustCompile(`URI="([^"]*)"`)
+
+// tagURI returns the value of the URI attribute of a tag that refers to a segment
+// and the position of the value in the line.
+func tagURI(line string) (string, []int, bool) {
+	found := false
+
+	for _, tag := range uriTags {
+		if strings.HasPrefix(line, tag) {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return "", nil, false
+	}
+
+	match := reTagURI.FindStringSubmatchIndex(line)
+	if match == nil {
+		return "", nil, false
+	}
+
+	return line[match[2]:match[3]], match[2:4], true
+}
+
+// rewriteTagURI adds the session ID to the URI attribute of a tag that refers to a
+// segment. Any other line is returned unmodified.
+func rewriteTagURI(line, sessionID string) string {
+	uri, pos, ok := tagURI(line)
+	if !ok {
+		return line
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "" || !isSegment(u.Path) {
+		return line
+	}
+
+	q := u.Query()
+	q.Set("session", sessionID)
+	u.RawQuery = q.Encode()
+
+	return line[:pos[0]] + u.String() + line[pos[1]:]
+}
+
 func headerSize(header http.Header) int64 {
 	var buffer bytes.Buffer
 
@@ -312,9 +500,14 @@ func (r *bodyReader) getSegments(dir string) []string {
 			continue
 		}
 
-		// Ignore comments
+		// Ignore comments, except tags that refer to a segment
 		if strings.HasPrefix(line, "#") {
-			continue
+			uri, _, ok := tagURI(line)
+			if !ok {
+				continue
+			}
+
+			line = uri
 		}
 
 		u, err := url.Parse(line)
@@ -328,8 +521,8 @@ func (r *bodyReader) getSegments(dir string) []string {
 			continue
 		}
 
-		// Ignore anything that doesn't end in .ts
-		if !strings.HasSuffix(u.Path, ".ts") {
+		// Ignore anything that isn't a segment
+		if !isSegment(u.Path) {
 			continue
 		}
 
@@ -389,9 +582,9 @@ func (g *sessionRewriter) rewriteHLS(sessionID string, requestURL *url.URL) {
 			continue
 		}
 
-		// Write comments unmodified
+		// Write comments unmodified, except tags that refer to a segment
 		if strings.HasPrefix(line, "#") {
-			buffer.WriteString(line + "\n")
+			buffer.WriteString(rewriteTagURI(line, sessionID) + "\n")
 			continue
 		}
 
@@ -401,8 +594,8 @@ func (g *sessionRewriter) rewriteHLS(sessionID string, requestURL *url.URL) {
 			continue
 		}
 
-		// Write anything that doesn't end in .m3u8 or .ts unmodified
-		if !strings.HasSuffix(u.Path, ".m3u8") && !strings.HasSuffix(u.Path, ".ts") {
+		// Write anything that isn't a .m3u8 or a segment unmodified
+		if !strings.HasSuffix(u.Path, ".m3u8") && !isSegment(u.Path) {
 			buffer.WriteString(line + "\n")
 			continue
 		}
@@ -0,0 +1,346 @@
+package session
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/datarhei/core/v16/session"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lithammer/shortuuid/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSegment(t *testing.T) {
+	require.True(t, isSegment("/memfs/foobar_0001.ts"))
+	require.True(t, isSegment("/memfs/foobar_0001.m4s"))
+	require.True(t, isSegment("/memfs/foobar_init.mp4"))
+	require.False(t, isSegment("/memfs/foobar.m3u8"))
+	require.False(t, isSegment("/memfs/foobar.jpg"))
+}
+
+func TestGetSegmentsFMP4(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:2
+#EXT-X-MAP:URI="foobar_init.mp4"
+#EXTINF:2.000000,
+foobar_0001.m4s
+#EXTINF:2.000000,
+foobar_0002.m4s
+#EXTINF:2.000000,
+https://example.com/foobar_0003.m4s
+`
+
+	r := &bodyReader{
+		reader: io.NopCloser(bytes.NewBufferString(playlist)),
+	}
+
+	_, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	segments := r.getSegments("/memfs")
+	require.Equal(t, []string{
+		"/memfs/foobar_init.mp4",
+		"/memfs/foobar_0001.m4s",
+		"/memfs/foobar_0002.m4s",
+	}, segments)
+}
+
+func TestGetSegmentsTS(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-VERSION:3
+#EXTINF:2.000000,
+foobar_0001.ts
+#EXTINF:2.000000,
+/other/foobar_0002.ts
+`
+
+	r := &bodyReader{
+		reader: io.NopCloser(bytes.NewBufferString(playlist)),
+	}
+
+	_, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	segments := r.getSegments("/memfs")
+	require.Equal(t, []string{
+		"/memfs/foobar_0001.ts",
+		"/other/foobar_0002.ts",
+	}, segments)
+}
+
+func TestRewriteFMP4(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MAP:URI="foobar_init.mp4"
+#EXTINF:2.000000,
+foobar_0001.m4s
+`
+
+	rewriter := &sessionRewriter{}
+	rewriter.buffer.WriteString(playlist)
+
+	u, err := url.Parse("/memfs/foobar.m3u8")
+	require.NoError(t, err)
+
+	rewriter.rewriteHLS("abc", u)
+
+	require.Equal(t, `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MAP:URI="foobar_init.mp4?session=abc"
+#EXTINF:2.000000,
+foobar_0001.m4s?session=abc
+`, rewriter.buffer.String())
+}
+
+func TestGetSegmentsLLHLS(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-VERSION:9
+#EXT-X-TARGETDURATION:2
+#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,PART-HOLD-BACK=1.0
+#EXT-X-PART-INF:PART-TARGET=0.333
+#EXT-X-MAP:URI="foobar_init.mp4"
+#EXTINF:2.000000,
+foobar_0001.m4s
+#EXT-X-PART:DURATION=0.333,URI="foobar_0002.0.m4s",INDEPENDENT=YES
+#EXT-X-PART:DURATION=0.333,URI="https://example.com/foobar_0002.1.m4s"
+#EXT-X-PRELOAD-HINT:TYPE=PART,URI="foobar_0002.2.m4s"
+`
+
+	r := &bodyReader{
+		reader: io.NopCloser(bytes.NewBufferString(playlist)),
+	}
+
+	_, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	segments := r.getSegments("/memfs")
+	require.Equal(t, []string{
+		"/memfs/foobar_init.mp4",
+		"/memfs/foobar_0001.m4s",
+		"/memfs/foobar_0002.0.m4s",
+		"/memfs/foobar_0002.2.m4s",
+	}, segments)
+}
+
+func TestRewriteLLHLS(t *testing.T) {
+	require.Equal(t,
+		`#EXT-X-PART:DURATION=0.333,URI="foobar_0002.0.m4s?session=abc",INDEPENDENT=YES`,
+		rewriteTagURI(`#EXT-X-PART:DURATION=0.333,URI="foobar_0002.0.m4s",INDEPENDENT=YES`, "abc"),
+	)
+
+	require.Equal(t,
+		`#EXT-X-PRELOAD-HINT:TYPE=PART,URI="foobar_0002.2.m4s?session=abc"`,
+		rewriteTagURI(`#EXT-X-PRELOAD-HINT:TYPE=PART,URI="foobar_0002.2.m4s"`, "abc"),
+	)
+
+	line := `#EXT-X-PART:DURATION=0.333,URI="https://example.com/foobar_0002.1.m4s"`
+	require.Equal(t, line, rewriteTagURI(line, "abc"))
+
+	line = `#EXT-X-KEY:METHOD=AES-128,URI="key.bin"`
+	require.Equal(t, line, rewriteTagURI(line, "abc"))
+}
+
+func TestSessionIDPattern(t *testing.T) {
+	_, err := HLSConfig{SessionIDPattern: "^[a-z"}.ToMiddleware()
+	require.Error(t, err)
+
+	require.Panics(t, func() {
+		NewHLSWithConfig(HLSConfig{SessionIDPattern: "^[a-z"})
+	})
+
+	collector := session.NewCollector(session.CollectorConfig{
+		SessionTimeout: time.Minute,
+	})
+	defer collector.Stop()
+
+	request := func(mw echo.MiddlewareFunc, sessionID string) int {
+		router := echo.New()
+		router.Use(mw)
+		router.GET("/memfs/foobar.m3u8", func(c echo.Context) error {
+			return c.String(http.StatusOK, "#EXTM3U\n#EXTINF:2.000000,\nfoobar_0001.ts\n")
+		})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memfs/foobar.m3u8?session="+sessionID, nil))
+
+		return rec.Code
+	}
+
+	newMiddleware := func(pattern string) echo.MiddlewareFunc {
+		mw, err := HLSConfig{
+			EgressCollector:  collector,
+			IngressCollector: session.NewNullCollector(),
+			SessionIDPattern: pattern,
+		}.ToMiddleware()
+		require.NoError(t, err)
+
+		return mw
+	}
+
+	// Default pattern
+	mw := newMiddleware("")
+
+	require.Equal(t, http.StatusOK, request(mw, shortuuid.New()))
+	require.Equal(t, http.StatusForbidden, request(mw, "player-0123456789abcdef"))
+
+	// Custom pattern
+	mw = newMiddleware(`^player-[0-9a-f]{16}$`)
+
+	require.Equal(t, http.StatusOK, request(mw, "player-0123456789abcdef"))
+	require.Equal(t, http.StatusForbidden, request(mw, "player-0123456789ABCDEF"))
+
+	// The session IDs generated by the middleware are always accepted
+	require.Equal(t, http.StatusOK, request(mw, shortuuid.New()))
+}
+
+func TestSessionIDPatternGenerated(t *testing.T) {
+	collector := session.NewCollector(session.CollectorConfig{
+		SessionTimeout: time.Minute,
+	})
+	defer collector.Stop()
+
+	router := echo.New()
+	router.Use(NewHLSWithConfig(HLSConfig{
+		EgressCollector:  collector,
+		IngressCollector: session.NewNullCollector(),
+		SessionIDPattern: `^player-[0-9a-f]{16}$`,
+	}))
+	router.GET("/memfs/master.m3u8", func(c echo.Context) error {
+		return c.String(http.StatusOK, "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1024\nfoobar.m3u8\n")
+	})
+	router.GET("/memfs/foobar.m3u8", func(c echo.Context) error {
+		return c.String(http.StatusOK, "#EXTM3U\n#EXTINF:2.000000,\nfoobar_0001.ts\n")
+	})
+	router.GET("/memfs/foobar.mpd", func(c echo.Context) error {
+		return c.String(http.StatusOK, mpd)
+	})
+
+	reSession := regexp.MustCompile(`foobar\.m3u8\?session=([^\s]+)`)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	// Master playlist
+	rec := get("/memfs/master.m3u8")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	match := reSession.FindStringSubmatch(rec.Body.String())
+	require.NotNil(t, match)
+	require.Equal(t, http.StatusOK, get("/memfs/foobar.m3u8?session="+match[1]).Code)
+
+	// Media playlist without a session
+	rec = get("/memfs/foobar.m3u8")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	match = reSession.FindStringSubmatch(rec.Body.String())
+	require.NotNil(t, match)
+	require.Equal(t, http.StatusOK, get("/memfs/foobar.m3u8?session="+match[1]).Code)
+
+	// DASH manifest without a session
+	require.Equal(t, http.StatusOK, get("/memfs/foobar.mpd").Code)
+
+	require.Equal(t, http.StatusForbidden, get("/memfs/foobar.m3u8?session=foobar").Code)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	collector := session.NewCollector(session.CollectorConfig{
+		MaxSessions:    1,
+		SessionTimeout: time.Minute,
+	})
+	defer collector.Stop()
+
+	router := echo.New()
+	router.Use(NewHLSWithConfig(HLSConfig{
+		EgressCollector:  collector,
+		IngressCollector: session.NewNullCollector(),
+		IdleTimeout:      100 * time.Millisecond,
+	}))
+	router.GET("/memfs/foobar.m3u8", func(c echo.Context) error {
+		return c.String(http.StatusOK, "#EXTM3U\n#EXTINF:2.000000,\nfoobar_0001.ts\n")
+	})
+	router.GET("/memfs/foobar_0001.ts", func(c echo.Context) error {
+		return c.String(http.StatusOK, "segment")
+	})
+
+	request := func(path string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec.Code
+	}
+
+	session1 := shortuuid.New()
+	session2 := shortuuid.New()
+
+	require.Equal(t, http.StatusOK, request("/memfs/foobar.m3u8?session="+session1))
+	require.Equal(t, http.StatusOK, request("/memfs/foobar_0001.ts?session="+session1))
+	require.Equal(t, uint64(1), collector.Sessions())
+
+	require.Equal(t, 509, request("/memfs/foobar.m3u8?session="+session2))
+
+	// The first session keeps requesting the playlist, but no segments
+	require.Eventually(t, func() bool {
+		request("/memfs/foobar.m3u8?session=" + session1)
+		return collector.Sessions() == 0
+	}, 2*time.Second, 50*time.Millisecond)
+
+	require.Equal(t, http.StatusOK, request("/memfs/foobar.m3u8?session="+session2))
+	require.Equal(t, http.StatusOK, request("/memfs/foobar_0001.ts?session="+session2))
+	require.Equal(t, uint64(1), collector.Sessions())
+}
+
+func TestSetMaxSessions(t *testing.T) {
+	collector := session.NewCollector(session.CollectorConfig{
+		SessionTimeout: time.Minute,
+	})
+	defer collector.Stop()
+
+	router := echo.New()
+	router.Use(NewHLSWithConfig(HLSConfig{
+		EgressCollector:  collector,
+		IngressCollector: session.NewNullCollector(),
+	}))
+	router.GET("/memfs/foobar.m3u8", func(c echo.Context) error {
+		return c.String(http.StatusOK, "#EXTM3U\n#EXTINF:2.000000,\nfoobar_0001.ts\n")
+	})
+	router.GET("/memfs/foobar_0001.ts", func(c echo.Context) error {
+		return c.String(http.StatusOK, "segment")
+	})
+
+	request := func(path string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec.Code
+	}
+
+	session1 := shortuuid.New()
+	session2 := shortuuid.New()
+
+	require.Equal(t, http.StatusOK, request("/memfs/foobar.m3u8?session="+session1))
+	require.Equal(t, http.StatusOK, request("/memfs/foobar_0001.ts?session="+session1))
+	require.Equal(t, uint64(1), collector.Sessions())
+
+	collector.SetMaxSessions(1)
+
+	// New sessions are rejected, the existing session continues
+	require.Equal(t, 509, request("/memfs/foobar.m3u8?session="+session2))
+	require.Equal(t, http.StatusOK, request("/memfs/foobar.m3u8?session="+session1))
+	require.Equal(t, http.StatusOK, request("/memfs/foobar_0001.ts?session="+session1))
+
+	collector.SetMaxSessions(0)
+
+	require.Equal(t, http.StatusOK, request("/memfs/foobar.m3u8?session="+session2))
+}
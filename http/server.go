@@ -54,6 +54,7 @@ import (
 
 	mwcache "github.com/datarhei/core/v16/http/middleware/cache"
 	mwcors "github.com/datarhei/core/v16/http/middleware/cors"
+	mwetag "github.com/datarhei/core/v16/http/middleware/etag"
 	mwgzip "github.com/datarhei/core/v16/http/middleware/gzip"
 	mwhlsrewrite "github.com/datarhei/core/v16/http/middleware/hlsrewrite"
 	mwiplimit "github.com/datarhei/core/v16/http/middleware/iplimit"
@@ -212,16 +213,22 @@ func NewServer(config Config) (Server, error) {
 		s.logger = log.New("HTTP")
 	}
 
+	if config.Sessions == nil {
+		config.Sessions, _ = session.New(session.Config{})
+	}
+
 	if config.JWT == nil {
-		s.handler.about = api.NewAbout(
-			config.Restream,
-			[]string{},
-		)
+		s.handler.about = api.NewAboutWithConfig(api.AboutConfig{
+			Restream: config.Restream,
+			Auths:    []string{},
+			Registry: config.Sessions,
+		})
 	} else {
-		s.handler.about = api.NewAbout(
-			config.Restream,
-			config.JWT.Validators(),
-		)
+		s.handler.about = api.NewAboutWithConfig(api.AboutConfig{
+			Restream: config.Restream,
+			Auths:    config.JWT.Validators(),
+			Registry: config.Sessions,
+		})
 	}
 
 	s.v3handler.log = api.NewLog(
@@ -280,10 +287,6 @@ func NewServer(config Config) (Server, error) {
 		s.middleware.refreshJWT = config.JWT.RefreshMiddleware()
 	}
 
-	if config.Sessions == nil {
-		config.Sessions, _ = session.New(session.Config{})
-	}
-
 	s.v3handler.session = api.NewSession(
 		config.Sessions,
 	)
@@ -534,6 +537,28 @@ func (s *server) setRoutes() {
 		v3.Use(s.middleware.accessJWT)
 	}
 
+	// Only the read-only listings that are polled by clients are answered with
+	// "304 Not Modified". Other endpoints may have side effects or return files
+	// that are too large to be buffered.
+	etagPaths := map[string]struct{}{
+		"/api/v3/process":  {},
+		"/api/v3/metadata": {},
+		"/api/v3/fs":       {},
+		"/api/v3/fs/:name": {},
+		"/api/v3/rtmp":     {},
+		"/api/v3/srt":      {},
+		"/api/v3/skills":   {},
+		"/api/v3/session":  {},
+		"/api/v3/log":      {},
+	}
+
+	v3.Use(mwetag.NewWithConfig(mwetag.Config{
+		Skipper: func(c echo.Context) bool {
+			_, ok := etagPaths[c.Path()]
+			return !ok
+		},
+	}))
+
 	v3.Use(gzipMiddleware)
 
 	s.setRoutesV3(v3)
@@ -545,6 +570,9 @@ func (s *server) setRoutesV3(v3 *echo.Group) {
 		s.router.GET("/api/v3/widget/process/:id", s.v3handler.widget.Get)
 	}
 
+	// v3 About
+	v3.GET("/about", s.handler.about.Overview)
+
 	// v3 Restreamer
 	if s.v3handler.restream != nil {
 		v3.GET("/skills", s.v3handler.restream.Skills)
@@ -642,6 +670,10 @@ func (s *server) setRoutesV3(v3 *echo.Group) {
 	if s.v3handler.session != nil {
 		v3.GET("/session", s.v3handler.session.Summary)
 		v3.GET("/session/active", s.v3handler.session.Active)
+
+		if !s.readOnly {
+			v3.PUT("/session/limits", s.v3handler.session.SetLimits)
+		}
 	}
 
 	// v3 Log
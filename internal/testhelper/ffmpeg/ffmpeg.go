@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"time"
 )
 
@@ -73,22 +74,25 @@ Output #0, hls, to './data/testsrc.m3u8':
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	go func(ctx context.Context) {
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
-
-		frame := uint64(0)
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				frame += 25
-				fmt.Fprintf(os.Stderr, "frame=%5d fps= 25 q=-1.0 Lsize=N/A time=00:00:02.32 bitrate=N/A speed=1.0x    \r", frame)
+	// An input that doesn't deliver any data doesn't make any progress
+	if !slices.Contains(os.Args, "stale") {
+		go func(ctx context.Context) {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+
+			frame := uint64(0)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					frame += 25
+					fmt.Fprintf(os.Stderr, "frame=%5d fps= 25 q=-1.0 Lsize=N/A time=00:00:02.32 bitrate=N/A speed=1.0x    \r", frame)
+				}
 			}
-		}
-	}(ctx)
+		}(ctx)
+	}
 
 	// Wait for interrupt signal to gracefully shutdown the app
 	quit := make(chan os.Signal, 1)
@@ -5,7 +5,15 @@ import (
 	"github.com/datarhei/core/v16/psutil"
 )
 
+// cpuSource provides the number of CPUs and their usage.
+type cpuSource interface {
+	CPUCounts(logical bool) (float64, error)
+	CPUPercent() (*psutil.CPUInfoStat, error)
+}
+
 type cpuCollector struct {
+	source cpuSource
+
 	ncpuDescr   *metric.Description
 	systemDescr *metric.Description
 	userDescr   *metric.Description
@@ -16,8 +24,13 @@ type cpuCollector struct {
 }
 
 func NewCPUCollector() metric.Collector {
+	return newCPUCollector(psutil.DefaultUtil)
+}
+
+func newCPUCollector(source cpuSource) *cpuCollector {
 	c := &cpuCollector{
-		ncpu: 1,
+		source: source,
+		ncpu:   1,
 	}
 
 	c.ncpuDescr = metric.NewDesc("cpu_ncpu", "Number of logical CPUs in the system", nil)
@@ -26,7 +39,7 @@ func NewCPUCollector() metric.Collector {
 	c.idleDescr = metric.NewDesc("cpu_idle", "Percentage of idle CPU", nil)
 	c.otherDescr = metric.NewDesc("cpu_other", "Percentage of CPU used for other subsystems", nil)
 
-	if ncpu, err := psutil.CPUCounts(true); err == nil {
+	if ncpu, err := c.source.CPUCounts(true); err == nil {
 		c.ncpu = ncpu
 	}
 
@@ -54,7 +67,7 @@ func (c *cpuCollector) Collect() metric.Metrics {
 
 	metrics.Add(metric.NewValue(c.ncpuDescr, c.ncpu))
 
-	stat, err := psutil.CPUPercent()
+	stat, err := c.source.CPUPercent()
 	if err != nil {
 		return metrics
 	}
@@ -1,24 +1,60 @@
 package monitor
 
 import (
+	"sync"
+	"time"
+
 	"github.com/datarhei/core/v16/monitor/metric"
 	"github.com/datarhei/core/v16/psutil"
+
+	"github.com/shirou/gopsutil/v3/disk"
 )
 
+// diskSource provides the usage and the IO counters of the disk a path is on.
+type diskSource interface {
+	DiskUsage(path string) (*disk.UsageStat, error)
+	DiskIOCounters(path string) (*disk.IOCountersStat, error)
+}
+
+type diskIO struct {
+	readBytes  uint64
+	writeBytes uint64
+	time       time.Time
+}
+
 type diskCollector struct {
-	path string
+	paths  []string
+	source diskSource
+	now    func() time.Time
+
+	io   map[string]diskIO
+	lock sync.Mutex
 
 	totalDescr *metric.Description
 	usageDescr *metric.Description
+	freeDescr  *metric.Description
+	readDescr  *metric.Description
+	writeDescr *metric.Description
 }
 
-func NewDiskCollector(path string) metric.Collector {
+// NewDiskCollector returns a collector for the disks the paths are on.
+func NewDiskCollector(paths ...string) metric.Collector {
+	return newDiskCollector(psutil.DefaultUtil, paths)
+}
+
+func newDiskCollector(source diskSource, paths []string) *diskCollector {
 	c := &diskCollector{
-		path: path,
+		paths:  paths,
+		source: source,
+		now:    time.Now,
+		io:     map[string]diskIO{},
 	}
 
 	c.totalDescr = metric.NewDesc("disk_total", "Total size of the disk in bytes", []string{"path"})
 	c.usageDescr = metric.NewDesc("disk_usage", "Number of used bytes on the disk", []string{"path"})
+	c.freeDescr = metric.NewDesc("disk_free", "Number of free bytes on the disk", []string{"path"})
+	c.readDescr = metric.NewDesc("disk_read", "Number of bytes read from the disk per second", []string{"path"})
+	c.writeDescr = metric.NewDesc("disk_write", "Number of bytes written to the disk per second", []string{"path"})
 
 	return c
 }
@@ -31,21 +67,71 @@ func (c *diskCollector) Describe() []*metric.Description {
 	return []*metric.Description{
 		c.totalDescr,
 		c.usageDescr,
+		c.freeDescr,
+		c.readDescr,
+		c.writeDescr,
 	}
 }
 
 func (c *diskCollector) Collect() metric.Metrics {
 	metrics := metric.NewMetrics()
 
-	stat, err := psutil.DiskUsage(c.path)
-	if err != nil {
-		return metrics
-	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	metrics.Add(metric.NewValue(c.totalDescr, float64(stat.Total), c.path))
-	metrics.Add(metric.NewValue(c.usageDescr, float64(stat.Used), c.path))
+	for _, path := range c.paths {
+		stat, err := c.source.DiskUsage(path)
+		if err != nil || stat == nil {
+			// The path may have disappeared. Forget about its IO counters such that
+			// no bogus rates are reported in case it comes back.
+			delete(c.io, path)
+			continue
+		}
+
+		metrics.Add(metric.NewValue(c.totalDescr, float64(stat.Total), path))
+		metrics.Add(metric.NewValue(c.usageDescr, float64(stat.Used), path))
+		metrics.Add(metric.NewValue(c.freeDescr, float64(stat.Free), path))
+
+		c.collectIO(metrics, path)
+	}
 
 	return metrics
 }
 
+// collectIO adds the read and write rates since the last collect. The rates are
+// only available from the second collect on.
+func (c *diskCollector) collectIO(metrics metric.Metrics, path string) {
+	counters, err := c.source.DiskIOCounters(path)
+	if err != nil || counters == nil {
+		delete(c.io, path)
+		return
+	}
+
+	current := diskIO{
+		readBytes:  counters.ReadBytes,
+		writeBytes: counters.WriteBytes,
+		time:       c.now(),
+	}
+
+	previous, ok := c.io[path]
+	c.io[path] = current
+
+	if !ok {
+		return
+	}
+
+	elapsed := current.time.Sub(previous.time).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	// The counters have been reset, e.g. because the path is now on a different device
+	if current.readBytes < previous.readBytes || current.writeBytes < previous.writeBytes {
+		return
+	}
+
+	metrics.Add(metric.NewValue(c.readDescr, float64(current.readBytes-previous.readBytes)/elapsed, path))
+	metrics.Add(metric.NewValue(c.writeDescr, float64(current.writeBytes-previous.writeBytes)/elapsed, path))
+}
+
 func (c *diskCollector) Stop() {}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDiskSource struct {
+	usage map[string]*disk.UsageStat
+	io    map[string]*disk.IOCountersStat
+}
+
+func (s *fakeDiskSource) DiskUsage(path string) (*disk.UsageStat, error) {
+	stat, ok := s.usage[path]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+
+	return stat, nil
+}
+
+func (s *fakeDiskSource) DiskIOCounters(path string) (*disk.IOCountersStat, error) {
+	stat, ok := s.io[path]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+
+	return stat, nil
+}
+
+func TestDiskCollector(t *testing.T) {
+	source := &fakeDiskSource{
+		usage: map[string]*disk.UsageStat{
+			"/data": {Total: 1000, Used: 400, Free: 600},
+			"/tmp":  {Total: 100, Used: 10, Free: 90},
+		},
+		io: map[string]*disk.IOCountersStat{
+			"/data": {ReadBytes: 1000, WriteBytes: 2000},
+		},
+	}
+
+	now := time.Now()
+
+	c := newDiskCollector(source, []string{"/data", "/tmp", "/missing"})
+	c.now = func() time.Time { return now }
+
+	for _, d := range c.Describe() {
+		require.Regexp(t, "^disk_", d.Name())
+	}
+
+	metrics := c.Collect()
+
+	require.Equal(t, 1000.0, metrics.Value("disk_total", "path", "/data").Val())
+	require.Equal(t, 400.0, metrics.Value("disk_usage", "path", "/data").Val())
+	require.Equal(t, 600.0, metrics.Value("disk_free", "path", "/data").Val())
+	require.Equal(t, 100.0, metrics.Value("disk_total", "path", "/tmp").Val())
+	require.Empty(t, metrics.Values("disk_total", "path", "/missing"))
+
+	// No rates with the first collect
+	require.Empty(t, metrics.Values("disk_read"))
+	require.Empty(t, metrics.Values("disk_write"))
+
+	now = now.Add(2 * time.Second)
+	source.io["/data"] = &disk.IOCountersStat{ReadBytes: 3000, WriteBytes: 6000}
+
+	metrics = c.Collect()
+
+	require.Equal(t, 1000.0, metrics.Value("disk_read", "path", "/data").Val())
+	require.Equal(t, 2000.0, metrics.Value("disk_write", "path", "/data").Val())
+	require.Empty(t, metrics.Values("disk_read", "path", "/tmp"))
+}
+
+func TestDiskCollectorDisappearingPath(t *testing.T) {
+	source := &fakeDiskSource{
+		usage: map[string]*disk.UsageStat{
+			"/data": {Total: 1000, Used: 400, Free: 600},
+		},
+		io: map[string]*disk.IOCountersStat{
+			"/data": {ReadBytes: 1000, WriteBytes: 2000},
+		},
+	}
+
+	now := time.Now()
+
+	c := newDiskCollector(source, []string{"/data"})
+	c.now = func() time.Time { return now }
+
+	c.Collect()
+
+	delete(source.usage, "/data")
+	delete(source.io, "/data")
+
+	now = now.Add(time.Second)
+
+	require.NotPanics(t, func() {
+		metrics := c.Collect()
+		require.Empty(t, metrics.All())
+	})
+
+	// The path comes back on a different device with lower counters
+	source.usage["/data"] = &disk.UsageStat{Total: 2000, Used: 100, Free: 1900}
+	source.io["/data"] = &disk.IOCountersStat{ReadBytes: 10, WriteBytes: 20}
+
+	now = now.Add(time.Second)
+
+	metrics := c.Collect()
+	require.Equal(t, 2000.0, metrics.Value("disk_total", "path", "/data").Val())
+	require.Empty(t, metrics.Values("disk_read"))
+
+	now = now.Add(time.Second)
+	source.io["/data"] = &disk.IOCountersStat{ReadBytes: 110, WriteBytes: 20}
+
+	metrics = c.Collect()
+	require.Equal(t, 100.0, metrics.Value("disk_read", "path", "/data").Val())
+	require.Equal(t, 0.0, metrics.Value("disk_write", "path", "/data").Val())
+}
@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/datarhei/core/v16/monitor/metric"
+)
+
+// NewPrometheusHandler returns a HTTP handler that renders the current values of all
+// collectors registered with the reader in the Prometheus text exposition format.
+// All values are exposed as gauges. The handler doesn't keep any state, concurrent
+// scrapes are serialized by the reader.
+func NewPrometheusHandler(reader Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buffer bytes.Buffer
+
+		WritePrometheus(&buffer, reader)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(buffer.Bytes())
+	})
+}
+
+// WritePrometheus writes the current values of all collectors registered with the reader
+// in the Prometheus text exposition format to w. The metrics are sorted by their name
+// and the values by their labels.
+func WritePrometheus(w io.Writer, reader Reader) error {
+	descriptions := reader.Describe()
+
+	sort.Slice(descriptions, func(i, j int) bool {
+		return descriptions[i].Name() < descriptions[j].Name()
+	})
+
+	patterns := []metric.Pattern{}
+	for _, d := range descriptions {
+		patterns = append(patterns, metric.NewPattern(d.Name()))
+	}
+
+	metrics := reader.Collect(patterns)
+
+	var buffer bytes.Buffer
+
+	for _, d := range descriptions {
+		values := metrics.Values(d.Name())
+		if len(values) == 0 {
+			continue
+		}
+
+		name := prometheusName(d.Name())
+
+		buffer.WriteString("# HELP " + name + " " + escapeHelp(d.Description()) + "\n")
+		buffer.WriteString("# TYPE " + name + " gauge\n")
+
+		lines := []string{}
+
+		for _, v := range values {
+			lines = append(lines, name+prometheusLabels(d.Labels(), v)+" "+prometheusValue(v.Val()))
+		}
+
+		sort.Strings(lines)
+
+		for _, line := range lines {
+			buffer.WriteString(line + "\n")
+		}
+	}
+
+	_, err := w.Write(buffer.Bytes())
+
+	return err
+}
+
+// prometheusName replaces all characters that are not allowed in a metric or label name with an underscore.
+func prometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
+// prometheusLabels returns the labels of a value in the order of the description.
+func prometheusLabels(names []string, v metric.Value) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	labels := []string{}
+
+	for _, name := range names {
+		labels = append(labels, prometheusName(name)+`="`+escapeLabelValue(v.L(name))+`"`)
+	}
+
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+func prometheusValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
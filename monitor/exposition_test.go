@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/datarhei/core/v16/monitor/metric"
+	"github.com/datarhei/core/v16/psutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCPUSource struct{}
+
+func (s *fakeCPUSource) CPUCounts(logical bool) (float64, error) {
+	return 8, nil
+}
+
+func (s *fakeCPUSource) CPUPercent() (*psutil.CPUInfoStat, error) {
+	return &psutil.CPUInfoStat{
+		System: 12.5,
+		User:   30,
+		Idle:   57,
+		Other:  0.5,
+	}, nil
+}
+
+func TestPrometheusCPU(t *testing.T) {
+	m := New(Config{})
+	m.Register(newCPUCollector(&fakeCPUSource{}))
+
+	var buffer bytes.Buffer
+
+	err := WritePrometheus(&buffer, m)
+	require.NoError(t, err)
+
+	golden, err := os.ReadFile("./fixtures/cpu.prom")
+	require.NoError(t, err)
+
+	require.Equal(t, string(golden), buffer.String())
+}
+
+type labelCollector struct {
+	descr *metric.Description
+}
+
+func (c *labelCollector) Prefix() string { return "test" }
+func (c *labelCollector) Stop()          {}
+
+func (c *labelCollector) Describe() []*metric.Description {
+	return []*metric.Description{c.descr}
+}
+
+func (c *labelCollector) Collect() metric.Metrics {
+	metrics := metric.NewMetrics()
+
+	metrics.Add(metric.NewValue(c.descr, 2, "b", `say "hello"`))
+	metrics.Add(metric.NewValue(c.descr, 1, "a", "back\\slash"))
+
+	return metrics
+}
+
+func TestPrometheusLabels(t *testing.T) {
+	m := New(Config{})
+	m.Register(&labelCollector{
+		descr: metric.NewDesc("test_value", "A value\nwith two lines", []string{"name", "text"}),
+	})
+
+	var buffer bytes.Buffer
+
+	err := WritePrometheus(&buffer, m)
+	require.NoError(t, err)
+
+	require.Equal(t, `# HELP test_value A value\nwith two lines
+# TYPE test_value gauge
+test_value{name="a",text="back\\slash"} 1
+test_value{name="b",text="say \"hello\""} 2
+`, buffer.String())
+}
+
+func TestPrometheusHandlerConcurrent(t *testing.T) {
+	m := New(Config{})
+	m.Register(newCPUCollector(&fakeCPUSource{}))
+
+	handler := NewPrometheusHandler(m)
+
+	golden, err := os.ReadFile("./fixtures/cpu.prom")
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+			require.Equal(t, string(golden), rec.Body.String())
+		}()
+	}
+
+	wg.Wait()
+}
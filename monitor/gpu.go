@@ -0,0 +1,189 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datarhei/core/v16/monitor/metric"
+)
+
+// GPUStat is the current state of a GPU.
+type GPUStat struct {
+	Index       string
+	Name        string
+	Utilization float64 // Percentage of time the GPU was busy
+	MemoryUsed  uint64  // Used memory in bytes
+	MemoryTotal uint64  // Total memory in bytes
+
+	EncoderSessions uint64
+	DecoderSessions *uint64 // nil if the backend doesn't report the decoder sessions
+}
+
+// GPUBackend provides the current state of all GPUs in the system.
+type GPUBackend interface {
+	Stats() ([]GPUStat, error)
+}
+
+type gpuCollector struct {
+	backend GPUBackend
+
+	ngpuDescr            *metric.Description
+	usageDescr           *metric.Description
+	memUsedDescr         *metric.Description
+	memTotalDescr        *metric.Description
+	encoderSessionsDescr *metric.Description
+	decoderSessionsDescr *metric.Description
+}
+
+// NewGPUCollector returns a collector for the GPUs provided by the backend. If the
+// backend is nil, the NVIDIA GPUs are queried with nvidia-smi. If no backend is
+// available, no metrics are collected.
+func NewGPUCollector(backend GPUBackend) metric.Collector {
+	c := &gpuCollector{
+		backend: backend,
+	}
+
+	if c.backend == nil {
+		if b, err := NewNvidiaBackend(); err == nil {
+			c.backend = b
+		}
+	}
+
+	c.ngpuDescr = metric.NewDesc("gpu_ngpu", "Number of GPUs in the system", nil)
+	c.usageDescr = metric.NewDesc("gpu_usage", "Percentage of GPU used", []string{"index"})
+	c.memUsedDescr = metric.NewDesc("gpu_mem_used", "Number of used bytes of the GPU memory", []string{"index"})
+	c.memTotalDescr = metric.NewDesc("gpu_mem_total", "Total size of the GPU memory in bytes", []string{"index"})
+	c.encoderSessionsDescr = metric.NewDesc("gpu_encoder_sessions", "Number of active encoder sessions", []string{"index"})
+	c.decoderSessionsDescr = metric.NewDesc("gpu_decoder_sessions", "Number of active decoder sessions", []string{"index"})
+
+	return c
+}
+
+func (c *gpuCollector) Stop() {}
+
+func (c *gpuCollector) Prefix() string {
+	return "gpu"
+}
+
+func (c *gpuCollector) Describe() []*metric.Description {
+	return []*metric.Description{
+		c.ngpuDescr,
+		c.usageDescr,
+		c.memUsedDescr,
+		c.memTotalDescr,
+		c.encoderSessionsDescr,
+		c.decoderSessionsDescr,
+	}
+}
+
+func (c *gpuCollector) Collect() metric.Metrics {
+	metrics := metric.NewMetrics()
+
+	if c.backend == nil {
+		return metrics
+	}
+
+	stats, err := c.backend.Stats()
+	if err != nil {
+		return metrics
+	}
+
+	metrics.Add(metric.NewValue(c.ngpuDescr, float64(len(stats))))
+
+	for _, stat := range stats {
+		metrics.Add(metric.NewValue(c.usageDescr, stat.Utilization, stat.Index))
+		metrics.Add(metric.NewValue(c.memUsedDescr, float64(stat.MemoryUsed), stat.Index))
+		metrics.Add(metric.NewValue(c.memTotalDescr, float64(stat.MemoryTotal), stat.Index))
+		metrics.Add(metric.NewValue(c.encoderSessionsDescr, float64(stat.EncoderSessions), stat.Index))
+
+		if stat.DecoderSessions != nil {
+			metrics.Add(metric.NewValue(c.decoderSessionsDescr, float64(*stat.DecoderSessions), stat.Index))
+		}
+	}
+
+	return metrics
+}
+
+type nvidiaBackend struct {
+	binary  string
+	timeout time.Duration
+}
+
+// NewNvidiaBackend returns a GPU backend that queries the NVIDIA GPUs with nvidia-smi.
+// It returns an error if nvidia-smi is not available. The number of decoder sessions
+// is not reported by nvidia-smi, therefore this metric is not available.
+func NewNvidiaBackend() (GPUBackend, error) {
+	binary, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi not found: %w", err)
+	}
+
+	return &nvidiaBackend{
+		binary:  binary,
+		timeout: 5 * time.Second,
+	}, nil
+}
+
+func (b *nvidiaBackend) Stats() ([]GPUStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, b.binary,
+		"--query-gpu=index,name,utilization.gpu,memory.used,memory.total,encoder.stats.sessionCount",
+		"--format=csv,noheader,nounits",
+	)
+
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("querying GPUs failed: %w", err)
+	}
+
+	return parseNvidiaStats(data)
+}
+
+// parseNvidiaStats parses the CSV output of nvidia-smi. The memory is reported in MiB.
+// Values that are not supported by a GPU are reported as "[N/A]" and are set to 0.
+func parseNvidiaStats(data []byte) ([]GPUStat, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+
+	stats := []GPUStat{}
+
+	for _, record := range records {
+		if len(record) != 6 {
+			return nil, fmt.Errorf("invalid output: expected 6 fields, found %d", len(record))
+		}
+
+		stat := GPUStat{
+			Index: strings.TrimSpace(record[0]),
+			Name:  strings.TrimSpace(record[1]),
+		}
+
+		stat.Utilization, _ = strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+
+		if mem, err := strconv.ParseUint(strings.TrimSpace(record[3]), 10, 64); err == nil {
+			stat.MemoryUsed = mem * 1024 * 1024
+		}
+
+		if mem, err := strconv.ParseUint(strings.TrimSpace(record[4]), 10, 64); err == nil {
+			stat.MemoryTotal = mem * 1024 * 1024
+		}
+
+		stat.EncoderSessions, _ = strconv.ParseUint(strings.TrimSpace(record[5]), 10, 64)
+
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockGPUBackend struct {
+	stats []GPUStat
+	err   error
+}
+
+func (b *mockGPUBackend) Stats() ([]GPUStat, error) {
+	return b.stats, b.err
+}
+
+func TestGPUCollector(t *testing.T) {
+	decoderSessions := uint64(2)
+
+	backend := &mockGPUBackend{
+		stats: []GPUStat{
+			{
+				Index:           "0",
+				Name:            "Tesla T4",
+				Utilization:     42,
+				MemoryUsed:      1024,
+				MemoryTotal:     4096,
+				EncoderSessions: 3,
+				DecoderSessions: &decoderSessions,
+			},
+			{
+				Index:       "1",
+				Name:        "Tesla T4",
+				MemoryTotal: 4096,
+			},
+		},
+	}
+
+	c := NewGPUCollector(backend)
+
+	require.Equal(t, "gpu", c.Prefix())
+
+	for _, d := range c.Describe() {
+		require.Regexp(t, "^gpu_", d.Name())
+	}
+
+	metrics := c.Collect()
+
+	require.Equal(t, 2.0, metrics.Value("gpu_ngpu").Val())
+	require.Equal(t, 42.0, metrics.Value("gpu_usage", "index", "0").Val())
+	require.Equal(t, 1024.0, metrics.Value("gpu_mem_used", "index", "0").Val())
+	require.Equal(t, 4096.0, metrics.Value("gpu_mem_total", "index", "0").Val())
+	require.Equal(t, 3.0, metrics.Value("gpu_encoder_sessions", "index", "0").Val())
+	require.Equal(t, 2.0, metrics.Value("gpu_decoder_sessions", "index", "0").Val())
+	require.Equal(t, 0.0, metrics.Value("gpu_usage", "index", "1").Val())
+	require.Equal(t, 4096.0, metrics.Value("gpu_mem_total", "index", "1").Val())
+
+	// The decoder sessions are only reported if the backend knows them
+	require.Len(t, metrics.Values("gpu_decoder_sessions"), 1)
+	require.Empty(t, metrics.Values("gpu_decoder_sessions", "index", "1"))
+
+	backend.err = fmt.Errorf("no GPUs")
+
+	require.Empty(t, c.Collect().All())
+}
+
+func TestGPUCollectorNoBackend(t *testing.T) {
+	c := NewGPUCollector(nil).(*gpuCollector)
+	c.backend = nil
+
+	require.Empty(t, c.Collect().All())
+}
+
+func TestParseNvidiaStats(t *testing.T) {
+	data := "0, Tesla T4, 42, 1024, 15360, 3\n1, NVIDIA GeForce RTX 3060, 0, 1, 12288, [N/A]\n"
+
+	stats, err := parseNvidiaStats([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, []GPUStat{
+		{
+			Index:           "0",
+			Name:            "Tesla T4",
+			Utilization:     42,
+			MemoryUsed:      1024 * 1024 * 1024,
+			MemoryTotal:     15360 * 1024 * 1024,
+			EncoderSessions: 3,
+		},
+		{
+			Index:       "1",
+			Name:        "NVIDIA GeForce RTX 3060",
+			MemoryUsed:  1024 * 1024,
+			MemoryTotal: 12288 * 1024 * 1024,
+		},
+	}, stats)
+
+	_, err = parseNvidiaStats([]byte("0, Tesla T4, 42\n"))
+	require.Error(t, err)
+}
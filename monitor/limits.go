@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"github.com/datarhei/core/v16/monitor/metric"
+	"github.com/datarhei/core/v16/restream"
+)
+
+type limitsCollector struct {
+	prefix      string
+	r           restream.Restreamer
+	cpuDescr    *metric.Description
+	memoryDescr *metric.Description
+	countDescr  *metric.Description
+}
+
+// NewLimitsCollector returns a collector that sums up the resource usage of all
+// running processes, as tracked by their limiters.
+func NewLimitsCollector(r restream.Restreamer) metric.Collector {
+	c := &limitsCollector{
+		prefix: "limits",
+		r:      r,
+	}
+
+	c.cpuDescr = metric.NewDesc("limits_cpu", "Summed CPU usage of all running processes in percent", []string{"type"})
+	c.memoryDescr = metric.NewDesc("limits_memory", "Summed memory usage of all running processes in bytes", []string{"type"})
+	c.countDescr = metric.NewDesc("limits_process", "Number of running processes and number of processes above one of their limits", []string{"state"})
+
+	return c
+}
+
+func (c *limitsCollector) Prefix() string {
+	return c.prefix
+}
+
+func (c *limitsCollector) Describe() []*metric.Description {
+	return []*metric.Description{
+		c.cpuDescr,
+		c.memoryDescr,
+		c.countDescr,
+	}
+}
+
+func (c *limitsCollector) Collect() metric.Metrics {
+	metrics := metric.NewMetrics()
+
+	cpu := map[string]float64{
+		"current": 0,
+		"average": 0,
+		"max":     0,
+		"limit":   0,
+	}
+
+	memory := map[string]float64{
+		"current": 0,
+		"average": 0,
+		"max":     0,
+		"limit":   0,
+	}
+
+	running := 0.0
+	overlimit := 0.0
+
+	for _, id := range c.r.GetProcessIDs("", "") {
+		state, _ := c.r.GetProcessState(id)
+		if state == nil {
+			continue
+		}
+
+		if state.State != "running" {
+			continue
+		}
+
+		running++
+
+		usage := state.Resources
+
+		cpu["current"] += usage.CPU.Current
+		cpu["average"] += usage.CPU.Average
+		cpu["max"] += usage.CPU.Max
+		cpu["limit"] += usage.CPU.Limit
+
+		memory["current"] += float64(usage.Memory.Current)
+		memory["average"] += usage.Memory.Average
+		memory["max"] += float64(usage.Memory.Max)
+		memory["limit"] += float64(usage.Memory.Limit)
+
+		if (usage.CPU.Limit > 0 && usage.CPU.Current > usage.CPU.Limit) || (usage.Memory.Limit > 0 && usage.Memory.Current > usage.Memory.Limit) {
+			overlimit++
+		}
+	}
+
+	for t, value := range cpu {
+		metrics.Add(metric.NewValue(c.cpuDescr, value, t))
+	}
+
+	for t, value := range memory {
+		metrics.Add(metric.NewValue(c.memoryDescr, value, t))
+	}
+
+	metrics.Add(metric.NewValue(c.countDescr, running, "running"))
+	metrics.Add(metric.NewValue(c.countDescr, overlimit, "overlimit"))
+
+	return metrics
+}
+
+func (c *limitsCollector) Stop() {}
@@ -1,20 +1,75 @@
 package monitor
 
 import (
+	"sync"
+	"time"
+
 	"github.com/datarhei/core/v16/monitor/metric"
 	"github.com/datarhei/core/v16/psutil"
+
+	"github.com/shirou/gopsutil/v3/net"
 )
 
+// netSource provides the IO counters of the network interfaces.
+type netSource interface {
+	NetIOCounters(pernic bool) ([]net.IOCountersStat, error)
+}
+
+type netIO struct {
+	stat net.IOCountersStat
+	time time.Time
+}
+
 type netCollector struct {
-	rxDescr *metric.Description
-	txDescr *metric.Description
+	source     netSource
+	interfaces map[string]struct{}
+	now        func() time.Time
+
+	io   map[string]netIO
+	lock sync.Mutex
+
+	rxDescr        *metric.Description
+	txDescr        *metric.Description
+	rxBytesDescr   *metric.Description
+	txBytesDescr   *metric.Description
+	rxPacketsDescr *metric.Description
+	txPacketsDescr *metric.Description
 }
 
+// NewNetCollector returns a collector for all network interfaces.
 func NewNetCollector() metric.Collector {
-	c := &netCollector{}
+	return NewNetworkCollector(nil)
+}
+
+// NewNetworkCollector returns a collector for the network interfaces with the given
+// names. If no names are given, all interfaces are collected. Besides the total
+// number of received and transmitted bytes, the rates of bytes and packets since
+// the last collect are reported.
+func NewNetworkCollector(interfaces []string) metric.Collector {
+	return newNetCollector(psutil.DefaultUtil, interfaces)
+}
+
+func newNetCollector(source netSource, interfaces []string) *netCollector {
+	c := &netCollector{
+		source: source,
+		now:    time.Now,
+		io:     map[string]netIO{},
+	}
+
+	if len(interfaces) != 0 {
+		c.interfaces = map[string]struct{}{}
+
+		for _, name := range interfaces {
+			c.interfaces[name] = struct{}{}
+		}
+	}
 
 	c.rxDescr = metric.NewDesc("net_rx", "Number of received bytes", []string{"interface"})
 	c.txDescr = metric.NewDesc("net_tx", "Number of transmitted bytes", []string{"interface"})
+	c.rxBytesDescr = metric.NewDesc("net_rx_bytes", "Number of received bytes per second", []string{"interface"})
+	c.txBytesDescr = metric.NewDesc("net_tx_bytes", "Number of transmitted bytes per second", []string{"interface"})
+	c.rxPacketsDescr = metric.NewDesc("net_rx_packets", "Number of received packets per second", []string{"interface"})
+	c.txPacketsDescr = metric.NewDesc("net_tx_packets", "Number of transmitted packets per second", []string{"interface"})
 
 	return c
 }
@@ -27,23 +82,80 @@ func (c *netCollector) Describe() []*metric.Description {
 	return []*metric.Description{
 		c.rxDescr,
 		c.txDescr,
+		c.rxBytesDescr,
+		c.txBytesDescr,
+		c.rxPacketsDescr,
+		c.txPacketsDescr,
 	}
 }
 
 func (c *netCollector) Collect() metric.Metrics {
 	metrics := metric.NewMetrics()
 
-	devs, err := psutil.NetIOCounters(true)
+	devs, err := c.source.NetIOCounters(true)
 	if err != nil {
 		return metrics
 	}
 
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	seen := map[string]struct{}{}
+
 	for _, dev := range devs {
+		if c.interfaces != nil {
+			if _, ok := c.interfaces[dev.Name]; !ok {
+				continue
+			}
+		}
+
+		seen[dev.Name] = struct{}{}
+
 		metrics.Add(metric.NewValue(c.rxDescr, float64(dev.BytesRecv), dev.Name))
 		metrics.Add(metric.NewValue(c.txDescr, float64(dev.BytesSent), dev.Name))
+
+		previous, ok := c.io[dev.Name]
+		c.io[dev.Name] = netIO{
+			stat: dev,
+			time: now,
+		}
+
+		// Rates are available from the second collect of an interface on
+		if !ok {
+			continue
+		}
+
+		elapsed := now.Sub(previous.time).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+
+		metrics.Add(metric.NewValue(c.rxBytesDescr, counterRate(previous.stat.BytesRecv, dev.BytesRecv, elapsed), dev.Name))
+		metrics.Add(metric.NewValue(c.txBytesDescr, counterRate(previous.stat.BytesSent, dev.BytesSent, elapsed), dev.Name))
+		metrics.Add(metric.NewValue(c.rxPacketsDescr, counterRate(previous.stat.PacketsRecv, dev.PacketsRecv, elapsed), dev.Name))
+		metrics.Add(metric.NewValue(c.txPacketsDescr, counterRate(previous.stat.PacketsSent, dev.PacketsSent, elapsed), dev.Name))
+	}
+
+	// Forget about interfaces that have been removed
+	for name := range c.io {
+		if _, ok := seen[name]; !ok {
+			delete(c.io, name)
+		}
 	}
 
 	return metrics
 }
 
 func (c *netCollector) Stop() {}
+
+// counterRate returns the rate per second between two counter values. If the counter has
+// been reset in the meantime, e.g. because the interface has been re-created, the
+// current value is taken as the difference.
+func counterRate(previous, current uint64, elapsed float64) float64 {
+	if current < previous {
+		return float64(current) / elapsed
+	}
+
+	return float64(current-previous) / elapsed
+}
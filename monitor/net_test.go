@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNetSource struct {
+	stats []net.IOCountersStat
+}
+
+func (s *fakeNetSource) NetIOCounters(pernic bool) ([]net.IOCountersStat, error) {
+	return s.stats, nil
+}
+
+func TestNetworkCollector(t *testing.T) {
+	source := &fakeNetSource{
+		stats: []net.IOCountersStat{
+			{Name: "eth0", BytesRecv: 1000, BytesSent: 2000, PacketsRecv: 10, PacketsSent: 20},
+			{Name: "lo", BytesRecv: 500, BytesSent: 500, PacketsRecv: 5, PacketsSent: 5},
+		},
+	}
+
+	now := time.Now()
+
+	c := newNetCollector(source, nil)
+	c.now = func() time.Time { return now }
+
+	for _, d := range c.Describe() {
+		require.Regexp(t, "^net_", d.Name())
+	}
+
+	metrics := c.Collect()
+
+	require.Equal(t, 1000.0, metrics.Value("net_rx", "interface", "eth0").Val())
+	require.Equal(t, 500.0, metrics.Value("net_tx", "interface", "lo").Val())
+	require.Empty(t, metrics.Values("net_rx_bytes"))
+
+	now = now.Add(2 * time.Second)
+	source.stats = []net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 3000, BytesSent: 6000, PacketsRecv: 30, PacketsSent: 60},
+		{Name: "lo", BytesRecv: 500, BytesSent: 500, PacketsRecv: 5, PacketsSent: 5},
+	}
+
+	metrics = c.Collect()
+
+	require.Equal(t, 1000.0, metrics.Value("net_rx_bytes", "interface", "eth0").Val())
+	require.Equal(t, 2000.0, metrics.Value("net_tx_bytes", "interface", "eth0").Val())
+	require.Equal(t, 10.0, metrics.Value("net_rx_packets", "interface", "eth0").Val())
+	require.Equal(t, 20.0, metrics.Value("net_tx_packets", "interface", "eth0").Val())
+	require.Equal(t, 0.0, metrics.Value("net_rx_bytes", "interface", "lo").Val())
+}
+
+func TestNetworkCollectorFilter(t *testing.T) {
+	source := &fakeNetSource{
+		stats: []net.IOCountersStat{
+			{Name: "eth0", BytesRecv: 1000},
+			{Name: "lo", BytesRecv: 500},
+		},
+	}
+
+	c := newNetCollector(source, []string{"eth0"})
+
+	metrics := c.Collect()
+
+	require.Equal(t, []string{"eth0"}, metrics.Labels("net_rx", "interface"))
+}
+
+func TestNetworkCollectorInterfaceChanges(t *testing.T) {
+	source := &fakeNetSource{
+		stats: []net.IOCountersStat{
+			{Name: "eth0", BytesRecv: 1000, PacketsRecv: 10},
+		},
+	}
+
+	now := time.Now()
+
+	c := newNetCollector(source, nil)
+	c.now = func() time.Time { return now }
+
+	c.Collect()
+
+	// eth0 is removed and tun0 is added
+	now = now.Add(time.Second)
+	source.stats = []net.IOCountersStat{
+		{Name: "tun0", BytesRecv: 100, PacketsRecv: 1},
+	}
+
+	metrics := c.Collect()
+	require.Empty(t, metrics.Values("net_rx_bytes"))
+	require.Equal(t, 100.0, metrics.Value("net_rx", "interface", "tun0").Val())
+
+	// eth0 comes back with reset counters
+	now = now.Add(time.Second)
+	source.stats = []net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 50, PacketsRecv: 1},
+		{Name: "tun0", BytesRecv: 300, PacketsRecv: 3},
+	}
+
+	metrics = c.Collect()
+	require.Empty(t, metrics.Values("net_rx_bytes", "interface", "eth0"))
+	require.Equal(t, 200.0, metrics.Value("net_rx_bytes", "interface", "tun0").Val())
+	require.Equal(t, 2.0, metrics.Value("net_rx_packets", "interface", "tun0").Val())
+
+	// Counters of an interface are reset between collects
+	now = now.Add(time.Second)
+	source.stats = []net.IOCountersStat{
+		{Name: "eth0", BytesRecv: 10, PacketsRecv: 1},
+	}
+
+	metrics = c.Collect()
+	require.Equal(t, 10.0, metrics.Value("net_rx_bytes", "interface", "eth0").Val())
+}
@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/datarhei/core/v16/monitor/metric"
+	"github.com/datarhei/core/v16/process"
+	"github.com/datarhei/core/v16/restream"
+)
+
+// ProcessLimiterLister lists the limiters of all running processes.
+type ProcessLimiterLister interface {
+	GetProcessLimiters() []restream.ProcessLimiter
+}
+
+type processCollector struct {
+	lister  ProcessLimiterLister
+	timeout time.Duration
+
+	cpuDescr         *metric.Description
+	memoryDescr      *metric.Description
+	cpuLimitDescr    *metric.Description
+	memoryLimitDescr *metric.Description
+}
+
+// NewProcessCollector returns a collector for the resource usage of each running
+// process, as tracked by its limiter.
+func NewProcessCollector(lister ProcessLimiterLister) metric.Collector {
+	c := &processCollector{
+		lister:  lister,
+		timeout: 100 * time.Millisecond,
+	}
+
+	c.cpuDescr = metric.NewDesc("process_cpu", "CPU usage of the process in percent", []string{"id", "reference"})
+	c.memoryDescr = metric.NewDesc("process_memory_bytes", "Memory usage of the process in bytes", []string{"id", "reference"})
+	c.cpuLimitDescr = metric.NewDesc("process_cpu_limit", "CPU limit of the process in percent", []string{"id", "reference"})
+	c.memoryLimitDescr = metric.NewDesc("process_memory_limit", "Memory limit of the process in bytes", []string{"id", "reference"})
+
+	return c
+}
+
+func (c *processCollector) Prefix() string {
+	return "process"
+}
+
+func (c *processCollector) Describe() []*metric.Description {
+	return []*metric.Description{
+		c.cpuDescr,
+		c.memoryDescr,
+		c.cpuLimitDescr,
+		c.memoryLimitDescr,
+	}
+}
+
+type processUsage struct {
+	limiter restream.ProcessLimiter
+	usage   process.Usage
+}
+
+func (c *processCollector) Collect() metric.Metrics {
+	metrics := metric.NewMetrics()
+
+	limiters := c.lister.GetProcessLimiters()
+	if len(limiters) == 0 {
+		return metrics
+	}
+
+	// A limiter that is being stopped may block for a while. Its usage is
+	// fetched in the background and the process is left out if it doesn't
+	// respond in time.
+	results := make(chan processUsage, len(limiters))
+
+	for _, l := range limiters {
+		go func(l restream.ProcessLimiter) {
+			results <- processUsage{
+				limiter: l,
+				usage:   l.Limiter.Usage(),
+			}
+		}(l)
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	for range limiters {
+		select {
+		case r := <-results:
+			id, reference := r.limiter.ID, r.limiter.Reference
+
+			metrics.Add(metric.NewValue(c.cpuDescr, r.usage.CPU.Current, id, reference))
+			metrics.Add(metric.NewValue(c.memoryDescr, float64(r.usage.Memory.Current), id, reference))
+			metrics.Add(metric.NewValue(c.cpuLimitDescr, r.usage.CPU.Limit, id, reference))
+			metrics.Add(metric.NewValue(c.memoryLimitDescr, float64(r.usage.Memory.Limit), id, reference))
+		case <-timer.C:
+			return metrics
+		}
+	}
+
+	return metrics
+}
+
+func (c *processCollector) Stop() {}
@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datarhei/core/v16/process"
+	"github.com/datarhei/core/v16/psutil"
+	"github.com/datarhei/core/v16/restream"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubLimiter struct {
+	usage process.Usage
+	block chan struct{}
+}
+
+func (l *stubLimiter) Start(process psutil.Process) error { return nil }
+func (l *stubLimiter) Stop()                              {}
+func (l *stubLimiter) Current() (float64, uint64)         { return l.usage.CPU.Current, l.usage.Memory.Current }
+func (l *stubLimiter) Limits() (float64, uint64)          { return l.usage.CPU.Limit, l.usage.Memory.Limit }
+
+func (l *stubLimiter) Usage() process.Usage {
+	if l.block != nil {
+		<-l.block
+	}
+
+	return l.usage
+}
+
+type stubLister struct {
+	limiters []restream.ProcessLimiter
+}
+
+func (l *stubLister) GetProcessLimiters() []restream.ProcessLimiter {
+	return l.limiters
+}
+
+func newStubLimiter(cpu, cpuLimit float64, memory, memoryLimit uint64) *stubLimiter {
+	l := &stubLimiter{}
+	l.usage.CPU.Current = cpu
+	l.usage.CPU.Limit = cpuLimit
+	l.usage.Memory.Current = memory
+	l.usage.Memory.Limit = memoryLimit
+
+	return l
+}
+
+func TestProcessCollector(t *testing.T) {
+	lister := &stubLister{
+		limiters: []restream.ProcessLimiter{
+			{ID: "foo", Reference: "ref1", Limiter: newStubLimiter(42, 50, 1024, 2048)},
+			{ID: "bar", Reference: "ref2", Limiter: newStubLimiter(7, 0, 512, 0)},
+		},
+	}
+
+	c := NewProcessCollector(lister)
+
+	for _, d := range c.Describe() {
+		require.Regexp(t, "^process_", d.Name())
+	}
+
+	metrics := c.Collect()
+
+	require.Equal(t, 42.0, metrics.Value("process_cpu", "id", "foo", "reference", "ref1").Val())
+	require.Equal(t, 1024.0, metrics.Value("process_memory_bytes", "id", "foo").Val())
+	require.Equal(t, 50.0, metrics.Value("process_cpu_limit", "id", "foo").Val())
+	require.Equal(t, 2048.0, metrics.Value("process_memory_limit", "id", "foo").Val())
+	require.Equal(t, 7.0, metrics.Value("process_cpu", "id", "bar", "reference", "ref2").Val())
+	require.Equal(t, 0.0, metrics.Value("process_cpu_limit", "id", "bar").Val())
+
+	lister.limiters = nil
+
+	require.Empty(t, c.Collect().All())
+}
+
+func TestProcessCollectorBlockingLimiter(t *testing.T) {
+	blocking := newStubLimiter(10, 0, 0, 0)
+	blocking.block = make(chan struct{})
+	defer close(blocking.block)
+
+	lister := &stubLister{
+		limiters: []restream.ProcessLimiter{
+			{ID: "foo", Limiter: newStubLimiter(42, 50, 1024, 2048)},
+			{ID: "stopping", Limiter: blocking},
+		},
+	}
+
+	c := NewProcessCollector(lister)
+
+	start := time.Now()
+	metrics := c.Collect()
+
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, 42.0, metrics.Value("process_cpu", "id", "foo").Val())
+	require.Empty(t, metrics.Values("process_cpu", "id", "stopping"))
+}
@@ -0,0 +1,14 @@
+package net
+
+import "crypto/subtle"
+
+// VerifyToken returns whether the token matches the expected token. The
+// comparison takes constant time in order to not leak the expected token
+// through timing differences. An empty token never matches.
+func VerifyToken(expected, token string) bool {
+	if len(token) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
@@ -0,0 +1,21 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyToken(t *testing.T) {
+	require.True(t, VerifyToken("foobar", "foobar"))
+	require.False(t, VerifyToken("foobar", "foobaz"))
+	require.False(t, VerifyToken("foobar", "foo"))
+	require.False(t, VerifyToken("foobar", ""))
+	require.False(t, VerifyToken("", ""))
+}
+
+func BenchmarkVerifyToken(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		VerifyToken("4b2a8c1f0e3d5a7b9c6e8f0a1b2c3d4e", "4b2a8c1f0e3d5a7b9c6e8f0a1b2c3d4f")
+	}
+}
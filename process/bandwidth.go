@@ -0,0 +1,66 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket is a token bucket rate limiter. Tokens are added with a constant
+// rate up to the size of the bucket. Taking more tokens than available puts
+// the bucket into debt, which has to be paid back before tokens are available
+// again.
+type tokenBucket struct {
+	rate   float64 // Tokens per second
+	burst  float64 // Size of the bucket
+	tokens float64
+	last   time.Time
+	lock   sync.Mutex
+}
+
+// newTokenBucket returns a full token bucket with the given rate per second and size.
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	if burst < rate {
+		burst = rate
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// Take takes n tokens from the bucket and returns how long to wait until the
+// tokens would have been available. A duration of 0 means that the rate is
+// not exceeded.
+func (b *tokenBucket) Take(n float64, now time.Time) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+
+		b.last = now
+	}
+
+	b.tokens -= n
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Reset fills up the bucket.
+func (b *tokenBucket) Reset(now time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens = b.burst
+	b.last = now
+}
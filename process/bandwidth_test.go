@@ -0,0 +1,57 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+
+	b := newTokenBucket(100, 200, now)
+
+	// The bucket starts full
+	require.Equal(t, time.Duration(0), b.Take(200, now))
+
+	// The bucket is empty, 50 tokens take half a second to become available
+	require.Equal(t, 500*time.Millisecond, b.Take(50, now))
+
+	// After one second, the debt is paid back and 50 tokens are available
+	now = now.Add(time.Second)
+	require.Equal(t, time.Duration(0), b.Take(50, now))
+
+	// Writing with the rate doesn't exceed it
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		require.Equal(t, time.Duration(0), b.Take(100, now))
+	}
+
+	// Sustained writing above the rate exceeds it more and more
+	var last time.Duration
+
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		delay := b.Take(150, now)
+		if i > 0 {
+			require.Greater(t, delay, last)
+		}
+		last = delay
+	}
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	now := time.Now()
+
+	b := newTokenBucket(100, 0, now)
+	require.Equal(t, 100.0, b.burst)
+
+	// An idle bucket doesn't fill beyond its size
+	now = now.Add(time.Hour)
+	require.Equal(t, time.Duration(0), b.Take(100, now))
+	require.Equal(t, time.Second, b.Take(100, now))
+
+	b.Reset(now)
+	require.Equal(t, time.Duration(0), b.Take(100, now))
+}
@@ -6,16 +6,24 @@ import (
 	"sync"
 	"time"
 
+	"github.com/datarhei/core/v16/log"
 	"github.com/datarhei/core/v16/psutil"
 )
 
 type LimitFunc func(cpu float64, memory uint64)
 
 type LimiterConfig struct {
-	CPU     float64       // Max. CPU usage in percent
-	Memory  uint64        // Max. memory usage in bytes
-	WaitFor time.Duration // Duration one of the limits has to be above the limit until OnLimit gets triggered
-	OnLimit LimitFunc     // Function to be triggered if limits are exceeded
+	CPU      float64       // Max. CPU usage in percent
+	Memory   uint64        // Max. memory usage in bytes
+	Egress   uint64        // Max. number of bytes written per second, only supported on Linux, no-op otherwise
+	WaitFor  time.Duration // Duration one of the limits has to be above the limit until OnLimit gets triggered
+	Interval time.Duration // Interval between collecting the CPU and memory usage, defaults to 1 second
+	OnLimit  LimitFunc     // Function to be triggered if limits are exceeded
+	Warn     float64       // Fraction of the limits (0, 1) above which OnWarn gets triggered, 0 disables the warning
+	OnWarn   LimitFunc     // Function to be triggered if one of the values crosses the warning threshold
+
+	MaxOpenFiles uint64     // Max. number of open file descriptors, only supported on Linux, 0 for no limit
+	Logger       log.Logger // Logger for problems with applying the limits
 }
 
 type Limiter interface {
@@ -30,6 +38,29 @@ type Limiter interface {
 
 	// Limits returns the defined CPU and memory limits. Values < 0 means no limit
 	Limits() (cpu float64, memory uint64)
+
+	// Usage returns the current, average and max. CPU and memory values together
+	// with the defined limits.
+	Usage() Usage
+}
+
+type Usage struct {
+	CPU struct {
+		Current float64 // percent
+		Average float64 // percent
+		Max     float64 // percent
+		Limit   float64 // percent
+	}
+	Memory struct {
+		Current uint64  // bytes
+		Average float64 // bytes
+		Max     uint64  // bytes
+		Limit   uint64  // bytes
+	}
+	OpenFiles struct {
+		Current uint64 // number of open file descriptors
+		Limit   uint64 // max. number of open file descriptors
+	}
 }
 
 type limiter struct {
@@ -37,39 +68,104 @@ type limiter struct {
 	lock    sync.Mutex
 	cancel  context.CancelFunc
 	onLimit LimitFunc
+	onWarn  LimitFunc
 
 	cpu              float64
 	cpuCurrent       float64
 	cpuLast          float64
+	cpuAverage       float64
+	cpuMax           float64
 	cpuLimitSince    time.Time
 	memory           uint64
 	memoryCurrent    uint64
 	memoryLast       uint64
+	memoryAverage    float64
+	memoryMax        uint64
 	memoryLimitSince time.Time
 	waitFor          time.Duration
+	interval         time.Duration
+	samples          uint64
+	warn             float64
+	warning          bool
+
+	egress           uint64
+	egressCurrent    float64
+	egressBucket     *tokenBucket
+	egressAbove      bool
+	egressLimitSince time.Time
+	written          uint64
+	writtenTime      time.Time
+	writtenValid     bool
+
+	maxOpenFiles uint64
+	openFiles    uint64
+
+	logger log.Logger
 }
 
 // NewLimiter returns a new Limiter
 func NewLimiter(config LimiterConfig) Limiter {
 	l := &limiter{
-		cpu:     config.CPU,
-		memory:  config.Memory,
-		waitFor: config.WaitFor,
-		onLimit: config.OnLimit,
+		cpu:      config.CPU,
+		memory:   config.Memory,
+		waitFor:  config.WaitFor,
+		interval: config.Interval,
+		onLimit:  config.OnLimit,
+		warn:     config.Warn,
+		onWarn:   config.OnWarn,
+		egress:   config.Egress,
+
+		maxOpenFiles: config.MaxOpenFiles,
+		logger:       config.Logger,
+	}
+
+	if l.logger == nil {
+		l.logger = log.New("")
+	}
+
+	if l.egress > 0 {
+		// Allow a burst of two seconds worth of bytes
+		l.egressBucket = newTokenBucket(float64(l.egress), 2*float64(l.egress), time.Now())
 	}
 
 	if l.onLimit == nil {
 		l.onLimit = func(float64, uint64) {}
 	}
 
+	if l.interval <= 0 {
+		l.interval = time.Second
+	}
+
+	if l.warn < 0 || l.warn >= 1 {
+		l.warn = 0
+	}
+
+	if l.onWarn == nil {
+		l.onWarn = func(float64, uint64) {}
+	}
+
 	return l
 }
 
 func (l *limiter) reset() {
 	l.cpuCurrent = 0
 	l.cpuLast = 0
+	l.cpuAverage = 0
+	l.cpuMax = 0
 	l.memoryCurrent = 0
 	l.memoryLast = 0
+	l.memoryAverage = 0
+	l.memoryMax = 0
+	l.samples = 0
+	l.warning = false
+	l.egressCurrent = 0
+	l.egressAbove = false
+	l.writtenValid = false
+	l.openFiles = 0
+
+	if l.egressBucket != nil {
+		l.egressBucket.Reset(time.Now())
+	}
 }
 
 func (l *limiter) Start(process psutil.Process) error {
@@ -84,6 +180,12 @@ func (l *limiter) Start(process psutil.Process) error {
 
 	l.proc = process
 
+	if l.maxOpenFiles > 0 {
+		if err := l.proc.SetMaxOpenFiles(l.maxOpenFiles); err != nil {
+			l.logger.Warn().WithError(err).WithField("limit", l.maxOpenFiles).Log("Failed to limit the number of open files")
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	l.cancel = cancel
 
@@ -109,7 +211,7 @@ func (l *limiter) Stop() {
 }
 
 func (l *limiter) ticker(ctx context.Context) {
-	ticker := time.NewTicker(time.Second)
+	ticker := time.NewTicker(l.interval)
 	defer ticker.Stop()
 
 	for {
@@ -138,6 +240,44 @@ func (l *limiter) collect(t time.Time) {
 		l.cpuLast, l.cpuCurrent = l.cpuCurrent, cpustat.System+cpustat.User+cpustat.Other
 	}
 
+	if n, err := l.proc.NumFDs(); err == nil {
+		l.openFiles = n
+	}
+
+	l.samples++
+
+	// Cumulative moving average over all samples since the limiter has been started
+	l.cpuAverage += (l.cpuCurrent - l.cpuAverage) / float64(l.samples)
+	l.memoryAverage += (float64(l.memoryCurrent) - l.memoryAverage) / float64(l.samples)
+
+	if l.cpuCurrent > l.cpuMax {
+		l.cpuMax = l.cpuCurrent
+	}
+
+	if l.memoryCurrent > l.memoryMax {
+		l.memoryMax = l.memoryCurrent
+	}
+
+	if l.warn > 0 {
+		isWarning := false
+
+		if l.cpu > 0 && l.cpuCurrent > l.cpu*l.warn {
+			isWarning = true
+		}
+
+		if l.memory > 0 && float64(l.memoryCurrent) > float64(l.memory)*l.warn {
+			isWarning = true
+		}
+
+		// Only trigger a warning when crossing the threshold. The warning is cleared
+		// as soon as all values are back below the threshold.
+		if isWarning && !l.warning {
+			go l.onWarn(l.cpuCurrent, l.memoryCurrent)
+		}
+
+		l.warning = isWarning
+	}
+
 	isLimitExceeded := false
 
 	if l.cpu > 0 {
@@ -170,11 +310,55 @@ func (l *limiter) collect(t time.Time) {
 		}
 	}
 
+	if l.egress > 0 && l.collectEgress(t) {
+		isLimitExceeded = true
+	}
+
 	if isLimitExceeded {
 		go l.onLimit(l.cpuCurrent, l.memoryCurrent)
 	}
 }
 
+// collectEgress updates the number of bytes written per second and returns whether the
+// process is writing more than allowed for longer than waitFor. The written bytes are
+// fed into a token bucket, such that short bursts above the limit are tolerated. If the
+// process can't report the written bytes, the limit is not enforced. The lock must be
+// held by the caller.
+func (l *limiter) collectEgress(t time.Time) bool {
+	written, err := l.proc.WrittenBytes()
+	if err != nil {
+		return false
+	}
+
+	defer func() {
+		l.written = written
+		l.writtenTime = t
+		l.writtenValid = true
+	}()
+
+	if !l.writtenValid || written < l.written {
+		return false
+	}
+
+	delta := written - l.written
+
+	if elapsed := t.Sub(l.writtenTime).Seconds(); elapsed > 0 {
+		l.egressCurrent = float64(delta) / elapsed
+	}
+
+	if l.egressBucket.Take(float64(delta), t) == 0 {
+		l.egressAbove = false
+		return false
+	}
+
+	if !l.egressAbove {
+		l.egressAbove = true
+		l.egressLimitSince = t
+	}
+
+	return t.Sub(l.egressLimitSince) >= l.waitFor
+}
+
 func (l *limiter) Current() (cpu float64, memory uint64) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
@@ -188,3 +372,25 @@ func (l *limiter) Current() (cpu float64, memory uint64) {
 func (l *limiter) Limits() (cpu float64, memory uint64) {
 	return l.cpu, l.memory
 }
+
+func (l *limiter) Usage() Usage {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	usage := Usage{}
+
+	usage.CPU.Current = l.cpuCurrent
+	usage.CPU.Average = l.cpuAverage
+	usage.CPU.Max = l.cpuMax
+	usage.CPU.Limit = l.cpu
+
+	usage.Memory.Current = l.memoryCurrent
+	usage.Memory.Average = l.memoryAverage
+	usage.Memory.Max = l.memoryMax
+	usage.Memory.Limit = l.memory
+
+	usage.OpenFiles.Current = l.openFiles
+	usage.OpenFiles.Limit = l.maxOpenFiles
+
+	return usage
+}
@@ -1,6 +1,11 @@
 package process
 
 import (
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -8,6 +13,7 @@ import (
 	"github.com/datarhei/core/v16/psutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type psproc struct{}
@@ -25,6 +31,18 @@ func (p *psproc) VirtualMemory() (uint64, error) {
 	return 197, nil
 }
 
+func (p *psproc) WrittenBytes() (uint64, error) {
+	return 0, nil
+}
+
+func (p *psproc) NumFDs() (uint64, error) {
+	return 0, nil
+}
+
+func (p *psproc) SetMaxOpenFiles(n uint64) error {
+	return nil
+}
+
 func (p *psproc) Stop() {}
 
 func TestCPULimit(t *testing.T) {
@@ -172,3 +190,269 @@ func TestMemoryLimitWaitFor(t *testing.T) {
 		return done
 	}, 10*time.Second, 1*time.Second)
 }
+
+type psprocSeries struct {
+	lock   sync.Mutex
+	cpu    []float64
+	memory []uint64
+}
+
+func (p *psprocSeries) CPUPercent() (*psutil.CPUInfoStat, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	cpu := p.cpu[0]
+	if len(p.cpu) > 1 {
+		p.cpu = p.cpu[1:]
+	}
+
+	return &psutil.CPUInfoStat{
+		System: cpu,
+	}, nil
+}
+
+func (p *psprocSeries) VirtualMemory() (uint64, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	memory := p.memory[0]
+	if len(p.memory) > 1 {
+		p.memory = p.memory[1:]
+	}
+
+	return memory, nil
+}
+
+func (p *psprocSeries) WrittenBytes() (uint64, error) {
+	return 0, nil
+}
+
+func (p *psprocSeries) NumFDs() (uint64, error) {
+	return 0, nil
+}
+
+func (p *psprocSeries) SetMaxOpenFiles(n uint64) error {
+	return nil
+}
+
+func (p *psprocSeries) Stop() {}
+
+func TestUsage(t *testing.T) {
+	l := NewLimiter(LimiterConfig{
+		CPU:    42,
+		Memory: 1000,
+	}).(*limiter)
+
+	l.lock.Lock()
+	l.proc = &psprocSeries{
+		cpu:    []float64{10, 30, 20},
+		memory: []uint64{100, 300, 200},
+	}
+	l.lock.Unlock()
+
+	now := time.Now()
+
+	l.collect(now)
+	l.collect(now)
+	l.collect(now)
+
+	usage := l.Usage()
+
+	assert.Equal(t, 20.0, usage.CPU.Current)
+	assert.Equal(t, 20.0, usage.CPU.Average)
+	assert.Equal(t, 30.0, usage.CPU.Max)
+	assert.Equal(t, 42.0, usage.CPU.Limit)
+
+	assert.Equal(t, uint64(200), usage.Memory.Current)
+	assert.Equal(t, 200.0, usage.Memory.Average)
+	assert.Equal(t, uint64(300), usage.Memory.Max)
+	assert.Equal(t, uint64(1000), usage.Memory.Limit)
+
+	l.lock.Lock()
+	l.reset()
+	l.lock.Unlock()
+
+	usage = l.Usage()
+
+	assert.Equal(t, 0.0, usage.CPU.Average)
+	assert.Equal(t, 0.0, usage.CPU.Max)
+}
+
+func TestWarn(t *testing.T) {
+	lock := sync.Mutex{}
+	warnings := 0
+
+	l := NewLimiter(LimiterConfig{
+		CPU:    50,
+		Memory: 1000,
+		Warn:   0.8,
+		OnWarn: func(float64, uint64) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			warnings++
+		},
+	}).(*limiter)
+
+	l.lock.Lock()
+	l.proc = &psprocSeries{
+		cpu:    []float64{10, 45, 48, 20, 10, 10},
+		memory: []uint64{100, 100, 100, 100, 900, 100},
+	}
+	l.lock.Unlock()
+
+	now := time.Now()
+
+	for i := 0; i < 6; i++ {
+		l.collect(now)
+	}
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return warnings == 2
+	}, time.Second, 100*time.Millisecond)
+
+	time.Sleep(200 * time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Equal(t, 2, warnings)
+}
+
+type psprocWritten struct {
+	psproc
+	written uint64
+}
+
+func (p *psprocWritten) WrittenBytes() (uint64, error) {
+	return p.written, nil
+}
+
+func TestEgressLimit(t *testing.T) {
+	limited := make(chan struct{}, 10)
+
+	l := NewLimiter(LimiterConfig{
+		Egress:  1000,
+		WaitFor: 3 * time.Second,
+		OnLimit: func(float64, uint64) {
+			limited <- struct{}{}
+		},
+	}).(*limiter)
+
+	proc := &psprocWritten{}
+	l.proc = proc
+
+	now := time.Now()
+	l.collect(now)
+
+	// Writing below the limit
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		proc.written += 800
+		l.collect(now)
+	}
+
+	assert.Equal(t, 800.0, l.egressCurrent)
+	assert.False(t, l.egressAbove)
+
+	// A short burst is tolerated
+	now = now.Add(time.Second)
+	proc.written += 1100
+	l.collect(now)
+	assert.False(t, l.egressAbove)
+
+	// Sustained writing above the limit triggers OnLimit after WaitFor
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Second)
+		proc.written += 2000
+		l.collect(now)
+	}
+
+	assert.True(t, l.egressAbove)
+	assert.Equal(t, 0, len(limited))
+
+	now = now.Add(time.Second)
+	proc.written += 2000
+	l.collect(now)
+
+	assert.Eventually(t, func() bool {
+		return len(limited) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestInterval(t *testing.T) {
+	l := NewLimiter(LimiterConfig{
+		CPU: 42,
+	}).(*limiter)
+
+	assert.Equal(t, time.Second, l.interval)
+
+	proc := &psprocSeries{
+		cpu:    []float64{30, 0, 60, 0, 30},
+		memory: []uint64{100},
+	}
+
+	l = NewLimiter(LimiterConfig{
+		CPU:      42,
+		WaitFor:  time.Hour,
+		Interval: 10 * time.Millisecond,
+	}).(*limiter)
+
+	l.Start(proc)
+	defer l.Stop()
+
+	assert.Eventually(t, func() bool {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		return l.samples >= 5
+	}, time.Second, 10*time.Millisecond)
+
+	// Samples of 0 don't disturb the statistics
+	usage := l.Usage()
+
+	assert.Equal(t, 60.0, usage.CPU.Max)
+	assert.False(t, math.IsNaN(usage.CPU.Average))
+	assert.Greater(t, usage.CPU.Average, 0.0)
+	assert.Less(t, usage.CPU.Average, 60.0)
+}
+
+func TestMaxOpenFiles(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("limiting open files is only supported on Linux")
+	}
+
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	proc, err := psutil.NewProcess(int32(cmd.Process.Pid))
+	require.NoError(t, err)
+
+	l := NewLimiter(LimiterConfig{
+		MaxOpenFiles: 64,
+	}).(*limiter)
+
+	err = l.Start(proc)
+	require.NoError(t, err)
+
+	defer l.Stop()
+
+	l.collect(time.Now())
+
+	usage := l.Usage()
+
+	assert.Greater(t, usage.OpenFiles.Current, uint64(0))
+	assert.Equal(t, uint64(64), usage.OpenFiles.Limit)
+
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(cmd.Process.Pid) + "/limits")
+	require.NoError(t, err)
+	assert.Regexp(t, `Max open files\s+64\s+64`, string(data))
+}
@@ -42,39 +42,61 @@ type Process interface {
 	// IsRunning returns whether the process is currently
 	// running or not.
 	IsRunning() bool
+
+	// Limiter returns the limiter that tracks the resource
+	// usage of the process.
+	Limiter() Limiter
 }
 
 // Config is the configuration of a process
 type Config struct {
-	Binary         string                // Path to the ffmpeg binary
-	Args           []string              // List of arguments for the binary
-	Reconnect      bool                  // Whether to restart the process if it exited
-	ReconnectDelay time.Duration         // Duration to wait before restarting the process
-	StaleTimeout   time.Duration         // Kill the process after this duration if it doesn't produce any output
-	LimitCPU       float64               // Kill the process if the CPU usage in percent is above this value
-	LimitMemory    uint64                // Kill the process if the memory consumption in bytes is above this value
-	LimitDuration  time.Duration         // Kill the process if the limits are exceeded for this duration
-	Parser         Parser                // A parser for the output of the process
-	OnStart        func()                // A callback which is called after the process started
-	OnExit         func()                // A callback which is called after the process exited
-	OnStateChange  func(from, to string) // A callback which is called after a state changed
-	Logger         log.Logger
+	Binary              string                                                   // Path to the ffmpeg binary
+	Args                []string                                                 // List of arguments for the binary
+	Reconnect           bool                                                     // Whether to restart the process if it exited
+	ReconnectDelay      time.Duration                                            // Duration to wait before restarting the process
+	ReconnectDelayMax   time.Duration                                            // Max. duration to wait before restarting the process, the delay doubles with every restart up to this value, 0 for a fixed delay
+	ReconnectResetAfter time.Duration                                            // Reset the delay to ReconnectDelay if the process has been running for this duration, defaults to ReconnectDelayMax
+	CrashLoopRestarts   int                                                      // Stop restarting the process if it has been restarted this many times within CrashLoopWindow, 0 to disable
+	CrashLoopWindow     time.Duration                                            // Duration of the window for the crash loop detection
+	StaleTimeout        time.Duration                                            // Kill the process after this duration if it doesn't produce any output
+	LimitCPU            float64                                                  // Kill the process if the CPU usage in percent is above this value
+	LimitMemory         uint64                                                   // Kill the process if the memory consumption in bytes is above this value
+	LimitEgress         uint64                                                   // Kill the process if it writes more bytes per second than this value, only supported on Linux
+	LimitOpenFiles      uint64                                                   // Max. number of open file descriptors of the process, only supported on Linux
+	LimitDuration       time.Duration                                            // Kill the process if the limits are exceeded for this duration
+	LimitWarn           float64                                                  // Log a warning if the CPU usage or memory consumption is above this fraction (0, 1) of the limits
+	LimitRestart        *RestartPolicy                                           // Restart the process with a backoff after it has been stopped because of exceeded limits, nil to restart it like after any other exit
+	Parser              Parser                                                   // A parser for the output of the process
+	OnBeforeStart       func() error                                             // A callback which is called in the background before the process starts, the process will not start if it returns an error
+	OnStart             func()                                                   // A callback which is called after the process started
+	OnGiveUp            func()                                                   // A callback which is called after the process stopped restarting because of a crash loop or exceeded limits, the order is "stop" afterwards
+	OnExit              func()                                                   // A callback which is called after the process exited
+	OnStateChange       func(from, to string)                                    // A callback which is called after a state changed
+	OnArgs              func(args []string, state string, restart bool) []string // A callback which is called before the process starts with a copy of the arguments, the current state, and whether the process is restarted after it ended without a stop order, returns the arguments to use
+	Logger              log.Logger
 }
 
 // Status represents the current status of a process
 type Status struct {
-	State    string        // State is the current state of the process. See stateType for the known states.
-	States   States        // States is the cumulative history of states the process had.
-	Order    string        // Order is the wanted condition of process, either "start" or "stop"
-	Duration time.Duration // Duration is the time since the last change of the state
-	Time     time.Time     // Time is the time of the last change of the state
-	CPU      struct {
+	State     string        // State is the current state of the process. See stateType for the known states.
+	States    States        // States is the cumulative history of states the process had.
+	Order     string        // Order is the wanted condition of process, either "start" or "stop"
+	Duration  time.Duration // Duration is the time since the last change of the state
+	Time      time.Time     // Time is the time of the last change of the state
+	Reconnect time.Duration // Reconnect is the delay before the next restart of the process
+	CrashLoop bool          // CrashLoop is whether the process has been stopped because it restarted too often
+	Restart   RestartState  // Restart is the state of the restart policy for exceeded limits
+	CPU       struct {
 		Current float64 // Used CPU in percent
+		Average float64 // Average used CPU in percent
+		Max     float64 // Max. used CPU in percent
 		Limit   float64 // Limit in percent
 	}
 	Memory struct {
-		Current uint64 // Used memory in bytes
-		Limit   uint64 // Limit in bytes
+		Current uint64  // Used memory in bytes
+		Average float64 // Average used memory in bytes
+		Max     uint64  // Max. used memory in bytes
+		Limit   uint64  // Limit in bytes
 	}
 }
 
@@ -161,8 +183,9 @@ type process struct {
 		lock   sync.Mutex
 	}
 	order struct {
-		order string
-		lock  sync.Mutex
+		order   string
+		pending uint64 // Sequence number of the start that waits for the onBeforeStart callback
+		lock    sync.Mutex
 	}
 	parser Parser
 	stale  struct {
@@ -172,19 +195,37 @@ type process struct {
 		lock    sync.Mutex
 	}
 	reconn struct {
-		enable bool
-		delay  time.Duration
-		timer  *time.Timer
-		lock   sync.Mutex
+		enable     bool
+		delay      time.Duration // Initial delay
+		delayMax   time.Duration // Max. delay for the exponential backoff
+		resetAfter time.Duration // Min. runtime of the process in order to reset the backoff
+		current    time.Duration // Delay of the next restart
+		scheduled  time.Duration // Delay of the currently scheduled restart
+		started    time.Time     // Time when the process started running
+		limited    bool          // Whether the next restart is because of exceeded limits
+		limitDelay time.Duration // Delay of the next restart because of exceeded limits
+		limitHit   bool          // Whether the process is being stopped because of exceeded limits
+		timer      *time.Timer
+		lock       sync.Mutex
+	}
+	crashloop struct {
+		restarts int           // Max. number of restarts within the window
+		window   time.Duration // Duration of the window
+		times    []time.Time   // Times of the restarts within the window
+		detected bool          // Whether a crash loop has been detected
 	}
+	limitRestart  *restartBackoff
 	killTimer     *time.Timer
 	killTimerLock sync.Mutex
 	logger        log.Logger
 	debuglogger   log.Logger
 	callbacks     struct {
+		onBeforeStart func() error
 		onStart       func()
+		onGiveUp      func()
 		onExit        func()
 		onStateChange func(from, to string)
+		onArgs        func(args []string, state string, restart bool) []string
 		lock          sync.Mutex
 	}
 	limits Limiter
@@ -228,24 +269,60 @@ func New(config Config) (Process, error) {
 
 	p.reconn.enable = config.Reconnect
 	p.reconn.delay = config.ReconnectDelay
+	p.reconn.delayMax = config.ReconnectDelayMax
+	p.reconn.resetAfter = config.ReconnectResetAfter
+	p.reconn.current = p.reconn.delay
+	p.reconn.scheduled = p.reconn.delay
+
+	if p.reconn.delayMax < p.reconn.delay {
+		p.reconn.delayMax = 0
+	}
+
+	if p.reconn.resetAfter <= 0 {
+		p.reconn.resetAfter = p.reconn.delayMax
+	}
+
+	if config.LimitRestart != nil {
+		p.limitRestart = newRestartBackoff(*config.LimitRestart)
+	}
+
+	if config.CrashLoopRestarts > 0 && config.CrashLoopWindow <= 0 {
+		return nil, fmt.Errorf("the crash loop detection requires a window")
+	}
+
+	p.crashloop.restarts = config.CrashLoopRestarts
+	p.crashloop.window = config.CrashLoopWindow
 
 	p.stale.last = time.Now()
 	p.stale.timeout = config.StaleTimeout
 
+	p.callbacks.onBeforeStart = config.OnBeforeStart
 	p.callbacks.onStart = config.OnStart
+	p.callbacks.onGiveUp = config.OnGiveUp
 	p.callbacks.onExit = config.OnExit
 	p.callbacks.onStateChange = config.OnStateChange
+	p.callbacks.onArgs = config.OnArgs
 
 	p.limits = NewLimiter(LimiterConfig{
-		CPU:     config.LimitCPU,
-		Memory:  config.LimitMemory,
-		WaitFor: config.LimitDuration,
+		CPU:          config.LimitCPU,
+		Memory:       config.LimitMemory,
+		Egress:       config.LimitEgress,
+		WaitFor:      config.LimitDuration,
+		MaxOpenFiles: config.LimitOpenFiles,
+		Logger:       p.logger,
 		OnLimit: func(cpu float64, memory uint64) {
 			p.logger.WithFields(log.Fields{
 				"cpu":    cpu,
 				"memory": memory,
 			}).Warn().Log("Stopping because limits are exceeded")
-			p.Kill(false)
+			p.onLimit()
+		},
+		Warn: config.LimitWarn,
+		OnWarn: func(cpu float64, memory uint64) {
+			p.logger.WithFields(log.Fields{
+				"cpu":    cpu,
+				"memory": memory,
+			}).Warn().Log("Approaching limits")
 		},
 	})
 
@@ -382,8 +459,7 @@ func (p *process) getStateString() string {
 
 // Status returns the current status of the process
 func (p *process) Status() Status {
-	cpu, memory := p.limits.Current()
-	cpuLimit, memoryLimit := p.limits.Limits()
+	usage := p.limits.Usage()
 
 	p.state.lock.Lock()
 	stateTime := p.state.time
@@ -395,19 +471,36 @@ func (p *process) Status() Status {
 	order := p.order.order
 	p.order.lock.Unlock()
 
+	p.reconn.lock.Lock()
+	reconnect := p.reconn.scheduled
+	crashloop := p.crashloop.detected
+	p.reconn.lock.Unlock()
+
+	restart := RestartState{}
+	if p.limitRestart != nil {
+		restart = p.limitRestart.State()
+	}
+
 	s := Status{
-		State:    stateString,
-		States:   states,
-		Order:    order,
-		Duration: time.Since(stateTime),
-		Time:     stateTime,
+		State:     stateString,
+		States:    states,
+		Order:     order,
+		Duration:  time.Since(stateTime),
+		Time:      stateTime,
+		Reconnect: reconnect,
+		CrashLoop: crashloop,
+		Restart:   restart,
 	}
 
-	s.CPU.Current = cpu
-	s.CPU.Limit = cpuLimit
+	s.CPU.Current = usage.CPU.Current
+	s.CPU.Average = usage.CPU.Average
+	s.CPU.Max = usage.CPU.Max
+	s.CPU.Limit = usage.CPU.Limit
 
-	s.Memory.Current = memory
-	s.Memory.Limit = memoryLimit
+	s.Memory.Current = usage.Memory.Current
+	s.Memory.Average = usage.Memory.Average
+	s.Memory.Max = usage.Memory.Max
+	s.Memory.Limit = usage.Memory.Limit
 
 	return s
 }
@@ -417,6 +510,10 @@ func (p *process) IsRunning() bool {
 	return p.isRunning()
 }
 
+func (p *process) Limiter() Limiter {
+	return p.limits
+}
+
 // Start will start the process and sets the order to "start". If the
 // process has alread the "start" order, nothing will be done. Returns
 // an error if start failed.
@@ -430,7 +527,9 @@ func (p *process) Start() error {
 
 	p.order.order = "start"
 
-	err := p.start()
+	p.resetReconnect()
+
+	err := p.start(false)
 	if err != nil {
 		p.debuglogger.WithFields(log.Fields{
 			"state": p.getStateString(),
@@ -444,9 +543,9 @@ func (p *process) Start() error {
 
 // start will start the process considering the current order. Returns an
 // error in case something goes wrong, and it will try to restart the process.
-func (p *process) start() error {
-	var err error
-
+// The restart flag tells whether the process is restarted after it ended
+// without a stop order.
+func (p *process) start(restart bool) error {
 	// Bail out if the process is already running
 	if p.isRunning() {
 		return nil
@@ -461,9 +560,65 @@ func (p *process) start() error {
 	// Stop any restart timer in order to start the process immediately
 	p.unreconnect()
 
+	args := p.args
+
+	// Let the callback decide about the arguments, based on the state the process is
+	// leaving, e.g. "failed" if the previous run didn't end well
+	if p.callbacks.onArgs != nil {
+		args = make([]string, len(p.args))
+		copy(args, p.args)
+
+		args = p.callbacks.onArgs(args, p.getStateString(), restart)
+	}
+
 	p.setState(stateStarting)
 
-	p.cmd = exec.Command(p.binary, p.args...)
+	// The callback may take a while. It is called in the background in order not to
+	// block the caller. The process is launched afterwards, unless it has been stopped
+	// in the meantime.
+	if p.callbacks.onBeforeStart != nil {
+		p.order.pending++
+
+		go p.beforeStart(p.order.pending, args)
+
+		return nil
+	}
+
+	return p.launch(args)
+}
+
+// beforeStart calls the onBeforeStart callback and launches the process if it is
+// still supposed to start.
+func (p *process) beforeStart(pending uint64, args []string) {
+	err := p.callbacks.onBeforeStart()
+
+	p.order.lock.Lock()
+	defer p.order.lock.Unlock()
+
+	// The start has been cancelled or superseded by another start
+	if p.order.pending != pending || p.getState() != stateStarting {
+		return
+	}
+
+	if err != nil {
+		p.setState(stateFailed)
+
+		p.parser.Parse(err.Error())
+		p.logger.WithError(err).Error().Log("Starting failed")
+		p.reconnect()
+
+		return
+	}
+
+	p.launch(args)
+}
+
+// launch runs the command of the process. It must be called while holding the
+// order lock and after the state has been set to starting.
+func (p *process) launch(args []string) error {
+	var err error
+
+	p.cmd = exec.Command(p.binary, args...)
 	p.cmd.Env = []string{}
 
 	p.stdout, err = p.cmd.StderrPipe()
@@ -488,6 +643,15 @@ func (p *process) start() error {
 
 	p.pid = int32(p.cmd.Process.Pid)
 
+	p.reconn.lock.Lock()
+	p.reconn.started = time.Now()
+	p.reconn.limitHit = false
+	p.reconn.lock.Unlock()
+
+	if p.limitRestart != nil {
+		p.limitRestart.Started(time.Now())
+	}
+
 	if proc, err := psutil.NewProcess(p.pid); err == nil {
 		p.limits.Start(proc)
 	}
@@ -571,6 +735,21 @@ func (p *process) stop(wait bool) error {
 		return nil
 	}
 
+	// If the process waits for the onBeforeStart callback, it hasn't been launched
+	// yet and the start is cancelled. A killed process will be started again.
+	if p.getState() == stateStarting {
+		p.order.pending++
+
+		p.setState(stateFinishing)
+		p.setState(stateFinished)
+
+		if p.order.order == "start" {
+			p.reconnect()
+		}
+
+		return nil
+	}
+
 	p.setState(stateFinishing)
 
 	p.logger.Info().Log("Stopping")
@@ -648,29 +827,159 @@ func (p *process) stop(wait bool) error {
 	return err
 }
 
-// reconnect will setup a timer to restart the  process
+// reconnect will setup a timer to restart the  process. It must be called
+// while holding the order lock.
 func (p *process) reconnect() {
-	// If restarting a process is not enabled, don't do anything
-	if !p.reconn.enable {
+	p.reconn.lock.Lock()
+	limited, limitDelay := p.reconn.limited, p.reconn.limitDelay
+	p.reconn.limited = false
+	p.reconn.lock.Unlock()
+
+	// If restarting a process is not enabled, don't do anything. A restart
+	// policy for exceeded limits applies regardless.
+	if !p.reconn.enable && !limited {
 		return
 	}
 
 	// Stop a currently running timer
 	p.unreconnect()
 
-	p.logger.Info().Log("Scheduling restart in %s", p.reconn.delay)
-
 	p.reconn.lock.Lock()
 	defer p.reconn.lock.Unlock()
 
-	p.reconn.timer = time.AfterFunc(p.reconn.delay, func() {
+	now := time.Now()
+
+	// Reset the backoff and the crash loop detection if the process has been
+	// running long enough
+	if !p.reconn.started.IsZero() {
+		if p.reconn.resetAfter > 0 && now.Sub(p.reconn.started) >= p.reconn.resetAfter {
+			p.reconn.current = p.reconn.delay
+			p.crashloop.times = nil
+		}
+
+		p.reconn.started = time.Time{}
+	}
+
+	if p.crashloop.restarts > 0 {
+		times := []time.Time{}
+		for _, t := range p.crashloop.times {
+			if now.Sub(t) < p.crashloop.window {
+				times = append(times, t)
+			}
+		}
+
+		p.crashloop.times = append(times, now)
+
+		if len(p.crashloop.times) > p.crashloop.restarts {
+			p.crashloop.detected = true
+			p.crashloop.times = nil
+
+			// The process requires a manual start in order to run again
+			p.order.order = "stop"
+
+			msg := fmt.Sprintf("Crash loop detected, the process restarted %d times within %s", p.crashloop.restarts, p.crashloop.window)
+
+			p.parser.Parse(msg)
+			p.logger.Error().Log(msg)
+
+			if p.callbacks.onGiveUp != nil {
+				go p.callbacks.onGiveUp()
+			}
+
+			return
+		}
+	}
+
+	delay := p.reconn.current
+
+	if limited {
+		delay = limitDelay
+	}
+
+	p.reconn.scheduled = delay
+
+	// Double the delay for the next restart, up to the max. delay
+	if !limited && p.reconn.delayMax > 0 {
+		if p.reconn.current == 0 {
+			p.reconn.current = time.Second
+		} else {
+			p.reconn.current *= 2
+		}
+
+		if p.reconn.current > p.reconn.delayMax {
+			p.reconn.current = p.reconn.delayMax
+		}
+	}
+
+	p.logger.Info().Log("Scheduling restart in %s", delay)
+
+	p.reconn.timer = time.AfterFunc(delay, func() {
 		p.order.lock.Lock()
 		defer p.order.lock.Unlock()
 
-		p.start()
+		p.start(true)
 	})
 }
 
+// onLimit stops the process because it exceeded its limits. With a restart policy, the
+// restart is scheduled with its backoff, or the process is stopped for good if the policy
+// gives up. Otherwise the process is restarted like after any other exit. The limiter
+// calls it repeatedly until the process exited, only the first call counts.
+func (p *process) onLimit() {
+	p.reconn.lock.Lock()
+	if p.reconn.limitHit {
+		p.reconn.lock.Unlock()
+		return
+	}
+	p.reconn.limitHit = true
+	p.reconn.lock.Unlock()
+
+	if p.limitRestart == nil {
+		p.Kill(false)
+		return
+	}
+
+	delay, ok := p.limitRestart.Limited(time.Now())
+	if !ok {
+		msg := fmt.Sprintf("Giving up restarting, the limits have been exceeded %d times in a row", p.limitRestart.State().Retries)
+
+		p.parser.Parse(msg)
+		p.logger.Error().Log(msg)
+
+		p.Stop(false)
+
+		if p.callbacks.onGiveUp != nil {
+			go p.callbacks.onGiveUp()
+		}
+
+		return
+	}
+
+	p.reconn.lock.Lock()
+	p.reconn.limited = true
+	p.reconn.limitDelay = delay
+	p.reconn.lock.Unlock()
+
+	p.Kill(false)
+}
+
+// resetReconnect resets the backoff and the crash loop detection
+func (p *process) resetReconnect() {
+	if p.limitRestart != nil {
+		p.limitRestart.Reset()
+	}
+
+	p.reconn.lock.Lock()
+	defer p.reconn.lock.Unlock()
+
+	p.reconn.current = p.reconn.delay
+	p.reconn.scheduled = p.reconn.delay
+	p.reconn.started = time.Time{}
+	p.reconn.limited = false
+	p.crashloop.times = nil
+	p.crashloop.detected = false
+}
+
 // unreconnect will stop the restart timer
 func (p *process) unreconnect() {
 	p.reconn.lock.Lock()
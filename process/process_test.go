@@ -1,6 +1,8 @@
 package process
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -162,6 +164,201 @@ func TestProcessFailed(t *testing.T) {
 	require.Equal(t, "failed", p.Status().State)
 }
 
+func TestProcessOnArgs(t *testing.T) {
+	states := []string{}
+	restarts := []bool{}
+	lock := sync.Mutex{}
+
+	p, _ := New(Config{
+		Binary: "sleep",
+		Args: []string{
+			"hello",
+		},
+		Reconnect:      true,
+		ReconnectDelay: time.Second,
+		StaleTimeout:   0,
+		OnArgs: func(args []string, state string, restart bool) []string {
+			lock.Lock()
+			states = append(states, state)
+			restarts = append(restarts, restart)
+			lock.Unlock()
+
+			if state == "failed" {
+				return []string{"10"}
+			}
+
+			return args
+		},
+	})
+
+	p.Start()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return len(states) == 2
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return p.Status().State == "running"
+	}, 5*time.Second, 100*time.Millisecond)
+
+	p.Stop(false)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	require.Equal(t, []string{"finished", "failed"}, states)
+	require.Equal(t, []bool{false, true}, restarts)
+}
+
+func TestProcessOnBeforeStart(t *testing.T) {
+	fail := true
+
+	p, _ := New(Config{
+		Binary: "sleep",
+		Args: []string{
+			"10",
+		},
+		Reconnect:    false,
+		StaleTimeout: 0,
+		OnBeforeStart: func() error {
+			if fail {
+				return fmt.Errorf("not allowed")
+			}
+
+			return nil
+		},
+	})
+
+	err := p.Start()
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return p.Status().State == "failed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	p.Stop(false)
+
+	fail = false
+
+	err = p.Start()
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return p.Status().State == "running"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	p.Stop(false)
+}
+
+func TestProcessOnBeforeStartCancel(t *testing.T) {
+	release := make(chan struct{})
+
+	p, _ := New(Config{
+		Binary: "sleep",
+		Args: []string{
+			"10",
+		},
+		Reconnect:    false,
+		StaleTimeout: 0,
+		OnBeforeStart: func() error {
+			<-release
+			return nil
+		},
+	})
+
+	// Start doesn't wait for the callback
+	err := p.Start()
+	require.NoError(t, err)
+	require.Equal(t, "starting", p.Status().State)
+
+	// Stopping cancels the pending start
+	err = p.Stop(true)
+	require.NoError(t, err)
+	require.Equal(t, "finished", p.Status().State)
+
+	close(release)
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, "finished", p.Status().State)
+	require.Equal(t, "stop", p.Status().Order)
+}
+
+func TestProcessReconnectBackoff(t *testing.T) {
+	p, _ := New(Config{
+		Binary:            "false",
+		Reconnect:         true,
+		ReconnectDelay:    100 * time.Millisecond,
+		ReconnectDelayMax: 400 * time.Millisecond,
+	})
+
+	p.Start()
+
+	require.Equal(t, 100*time.Millisecond, p.Status().Reconnect)
+
+	require.Eventually(t, func() bool {
+		return p.Status().Reconnect == 400*time.Millisecond
+	}, 5*time.Second, 10*time.Millisecond)
+
+	p.Stop(false)
+
+	// Starting again resets the backoff
+	p.Start()
+
+	require.Equal(t, 100*time.Millisecond, p.Status().Reconnect)
+
+	p.Stop(false)
+}
+
+func TestProcessCrashLoop(t *testing.T) {
+	_, err := New(Config{
+		Binary:            "false",
+		CrashLoopRestarts: 3,
+	})
+	require.Error(t, err, "a crash loop detection without window must be rejected")
+
+	gaveup := make(chan struct{}, 1)
+
+	p, _ := New(Config{
+		Binary:            "false",
+		Reconnect:         true,
+		ReconnectDelay:    10 * time.Millisecond,
+		CrashLoopRestarts: 3,
+		CrashLoopWindow:   10 * time.Second,
+		OnGiveUp: func() {
+			gaveup <- struct{}{}
+		},
+	})
+
+	p.Start()
+
+	require.Eventually(t, func() bool {
+		return p.Status().CrashLoop
+	}, 5*time.Second, 10*time.Millisecond)
+
+	select {
+	case <-gaveup:
+	case <-time.After(time.Second):
+		require.Fail(t, "OnGiveUp has not been called")
+	}
+
+	status := p.Status()
+
+	require.Equal(t, "failed", status.State)
+	require.Equal(t, "stop", status.Order)
+	require.Equal(t, uint64(4), status.States.Failed)
+
+	p.Start()
+
+	require.False(t, p.Status().CrashLoop)
+	require.Equal(t, "start", p.Status().Order)
+
+	p.Stop(false)
+}
+
 func TestFFmpegWaitStop(t *testing.T) {
 	binary, err := testhelper.BuildBinary("sigintwait", "../internal/testhelper")
 	require.NoError(t, err, "Failed to build helper program")
@@ -0,0 +1,115 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+// RestartPolicy defines how a process is restarted after it has been stopped because
+// it exceeded its limits. The delay before a restart starts with BaseDelay and doubles
+// with every consecutive limit hit up to MaxDelay.
+type RestartPolicy struct {
+	MaxRetries int           // Max. number of consecutive restarts, 0 for unlimited
+	BaseDelay  time.Duration // Delay before the first restart
+	MaxDelay   time.Duration // Max. delay before a restart, defaults to BaseDelay
+	ResetAfter time.Duration // Reset the backoff if the process has been running for this duration, defaults to MaxDelay
+}
+
+// RestartState is the current state of the backoff of a restart policy.
+type RestartState struct {
+	Retries int           // Number of consecutive restarts
+	Delay   time.Duration // Delay of the last scheduled restart
+	GaveUp  bool          // Whether the max. number of restarts has been reached
+}
+
+type restartBackoff struct {
+	policy  RestartPolicy
+	state   RestartState
+	started time.Time
+	lock    sync.Mutex
+}
+
+// newRestartBackoff returns the backoff for a restart policy.
+func newRestartBackoff(policy RestartPolicy) *restartBackoff {
+	if policy.MaxRetries < 0 {
+		policy.MaxRetries = 0
+	}
+
+	if policy.BaseDelay < 0 {
+		policy.BaseDelay = 0
+	}
+
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+
+	if policy.ResetAfter <= 0 {
+		policy.ResetAfter = policy.MaxDelay
+	}
+
+	return &restartBackoff{
+		policy: policy,
+	}
+}
+
+// Started records the time the process started running.
+func (b *restartBackoff) Started(now time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.started = now
+}
+
+// Limited is called when the process exceeded its limits. It returns the delay before
+// the process should be restarted, or false if it shouldn't be restarted anymore. The
+// backoff is reset first if the process has been running for at least ResetAfter.
+func (b *restartBackoff) Limited(now time.Time) (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.started.IsZero() && b.policy.ResetAfter > 0 && now.Sub(b.started) >= b.policy.ResetAfter {
+		b.state = RestartState{}
+	}
+
+	b.started = time.Time{}
+
+	if b.state.GaveUp {
+		return 0, false
+	}
+
+	if b.policy.MaxRetries > 0 && b.state.Retries >= b.policy.MaxRetries {
+		b.state.GaveUp = true
+		return 0, false
+	}
+
+	delay := b.policy.BaseDelay
+	for i := 0; i < b.state.Retries && delay < b.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > b.policy.MaxDelay {
+		delay = b.policy.MaxDelay
+	}
+
+	b.state.Retries++
+	b.state.Delay = delay
+
+	return delay, true
+}
+
+// Reset resets the backoff, e.g. after the process has been started manually.
+func (b *restartBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.state = RestartState{}
+	b.started = time.Time{}
+}
+
+// State returns the current state of the backoff.
+func (b *restartBackoff) State() RestartState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state
+}
@@ -0,0 +1,147 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartBackoff(t *testing.T) {
+	b := newRestartBackoff(RestartPolicy{
+		BaseDelay:  time.Second,
+		MaxDelay:   10 * time.Second,
+		ResetAfter: time.Minute,
+	})
+
+	now := time.Now()
+
+	delays := []time.Duration{}
+
+	for i := 0; i < 6; i++ {
+		b.Started(now)
+		now = now.Add(5 * time.Second)
+
+		delay, ok := b.Limited(now)
+		require.True(t, ok)
+
+		delays = append(delays, delay)
+	}
+
+	require.Equal(t, []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}, delays)
+
+	require.Equal(t, RestartState{Retries: 6, Delay: 10 * time.Second}, b.State())
+
+	// A stable run resets the backoff
+	b.Started(now)
+	now = now.Add(time.Minute)
+
+	delay, ok := b.Limited(now)
+	require.True(t, ok)
+	require.Equal(t, time.Second, delay)
+	require.Equal(t, RestartState{Retries: 1, Delay: time.Second}, b.State())
+}
+
+func TestRestartBackoffMaxRetries(t *testing.T) {
+	b := newRestartBackoff(RestartPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Second,
+	})
+
+	require.Equal(t, time.Second, b.policy.MaxDelay)
+	require.Equal(t, time.Second, b.policy.ResetAfter)
+
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		b.Started(now)
+
+		delay, ok := b.Limited(now)
+		require.True(t, ok)
+		require.Equal(t, time.Second, delay)
+	}
+
+	b.Started(now)
+
+	_, ok := b.Limited(now)
+	require.False(t, ok)
+	require.True(t, b.State().GaveUp)
+
+	// A stable run resets the backoff
+	b.Started(now)
+	_, ok = b.Limited(now.Add(time.Minute))
+	require.True(t, ok)
+
+	b.Reset()
+	require.Equal(t, RestartState{}, b.State())
+}
+
+func TestProcessLimitRestart(t *testing.T) {
+	gaveup := make(chan struct{}, 1)
+
+	p, _ := New(Config{
+		Binary: "sleep",
+		Args:   []string{"60"},
+		OnGiveUp: func() {
+			gaveup <- struct{}{}
+		},
+		LimitRestart: &RestartPolicy{
+			MaxRetries: 2,
+			BaseDelay:  10 * time.Millisecond,
+			MaxDelay:   time.Second,
+			ResetAfter: time.Hour,
+		},
+	})
+
+	proc := p.(*process)
+
+	p.Start()
+
+	for i := 1; i <= 2; i++ {
+		require.Eventually(t, func() bool {
+			return p.Status().State == "running"
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// The limiter reports the exceeded limits until the process exited
+		proc.onLimit()
+		proc.onLimit()
+		proc.onLimit()
+
+		require.Eventually(t, func() bool {
+			return p.Status().Restart.Retries == i
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	require.Equal(t, 20*time.Millisecond, p.Status().Restart.Delay)
+
+	// The policy gives up with the third limit hit
+	require.Eventually(t, func() bool {
+		return p.Status().State == "running"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	proc.onLimit()
+
+	require.Eventually(t, func() bool {
+		status := p.Status()
+		return status.Restart.GaveUp && status.Order == "stop" && !p.IsRunning()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	select {
+	case <-gaveup:
+	case <-time.After(time.Second):
+		require.Fail(t, "OnGiveUp has not been called")
+	}
+
+	p.Start()
+
+	require.Equal(t, RestartState{}, p.Status().Restart)
+
+	p.Stop(true)
+}
@@ -2,6 +2,11 @@ package psutil
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +16,18 @@ import (
 type Process interface {
 	CPUPercent() (*CPUInfoStat, error)
 	VirtualMemory() (uint64, error)
+
+	// WrittenBytes returns the number of bytes the process has written so far, including
+	// writes to sockets and pipes. It is only supported on Linux.
+	WrittenBytes() (uint64, error)
+
+	// NumFDs returns the number of open file descriptors of the process.
+	NumFDs() (uint64, error)
+
+	// SetMaxOpenFiles sets the max. number of open file descriptors of the process. It
+	// is only supported on Linux.
+	SetMaxOpenFiles(n uint64) error
+
 	Stop()
 }
 
@@ -139,6 +156,44 @@ func (p *process) CPUPercent() (*CPUInfoStat, error) {
 	return s, nil
 }
 
+func (p *process) WrittenBytes() (uint64, error) {
+	if runtime.GOOS != "linux" {
+		return 0, fmt.Errorf("not supported on %s", runtime.GOOS)
+	}
+
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(p.pid)) + "/io")
+	if err != nil {
+		return 0, err
+	}
+
+	return parseWrittenBytes(data)
+}
+
+// parseWrittenBytes returns the value of the wchar field of the /proc/[pid]/io file. In
+// contrast to write_bytes, it counts all bytes passed to write syscalls, not only those
+// that have been written to the storage.
+func parseWrittenBytes(data []byte) (uint64, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		name, value, found := strings.Cut(line, ":")
+		if !found || name != "wchar" {
+			continue
+		}
+
+		return strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	}
+
+	return 0, fmt.Errorf("wchar not found")
+}
+
+func (p *process) NumFDs() (uint64, error) {
+	n, err := p.proc.NumFDs()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(n), nil
+}
+
 func (p *process) VirtualMemory() (uint64, error) {
 	info, err := p.proc.MemoryInfo()
 	if err != nil {
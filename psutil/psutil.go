@@ -72,6 +72,7 @@ type Util interface {
 	CPUCounts(logical bool) (float64, error)
 	CPUPercent() (*CPUInfoStat, error)
 	DiskUsage(path string) (*disk.UsageStat, error)
+	DiskIOCounters(path string) (*disk.IOCountersStat, error)
 	VirtualMemory() (*MemoryInfoStat, error)
 	NetIOCounters(pernic bool) ([]net.IOCountersStat, error)
 	Process(pid int32) (Process, error)
@@ -381,6 +382,55 @@ func DiskUsage(path string) (*disk.UsageStat, error) {
 	return DefaultUtil.DiskUsage(path)
 }
 
+// DiskIOCounters returns the IO counters of the device that is mounted at the
+// mountpoint which contains the path.
+func (u *util) DiskIOCounters(path string) (*disk.IOCountersStat, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	device := ""
+	mountpoint := ""
+
+	for _, p := range partitions {
+		if !strings.HasPrefix(path, p.Mountpoint) {
+			continue
+		}
+
+		if p.Mountpoint != "/" && len(path) > len(p.Mountpoint) && path[len(p.Mountpoint)] != '/' {
+			continue
+		}
+
+		if len(p.Mountpoint) >= len(mountpoint) {
+			mountpoint = p.Mountpoint
+			device = p.Device
+		}
+	}
+
+	if len(device) == 0 {
+		return nil, fmt.Errorf("no device found for %s", path)
+	}
+
+	name := device[strings.LastIndex(device, "/")+1:]
+
+	counters, err := disk.IOCounters(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, ok := counters[name]
+	if !ok {
+		return nil, fmt.Errorf("no IO counters found for device %s", device)
+	}
+
+	return &stat, nil
+}
+
+func DiskIOCounters(path string) (*disk.IOCountersStat, error) {
+	return DefaultUtil.DiskIOCounters(path)
+}
+
 func (u *util) VirtualMemory() (*MemoryInfoStat, error) {
 	info, err := mem.VirtualMemory()
 	if err != nil {
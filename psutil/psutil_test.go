@@ -92,3 +92,14 @@ func TestCgroup(t *testing.T) {
 	assert.Equal(t, uint64(9223372036854771712), mem.Total)
 	assert.Equal(t, uint64(34070528), mem.Used)
 }
+
+func TestParseWrittenBytes(t *testing.T) {
+	data := "rchar: 323934931\nwchar: 323929600\nsyscr: 632687\nsyscw: 632675\nread_bytes: 0\nwrite_bytes: 323932160\n"
+
+	written, err := parseWrittenBytes([]byte(data))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(323929600), written)
+
+	_, err = parseWrittenBytes([]byte("rchar: 42\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,14 @@
+package psutil
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func (p *process) SetMaxOpenFiles(n uint64) error {
+	limit := &unix.Rlimit{
+		Cur: n,
+		Max: n,
+	}
+
+	return unix.Prlimit(int(p.pid), unix.RLIMIT_NOFILE, limit, nil)
+}
@@ -0,0 +1,50 @@
+package psutil
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxOpenFiles(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	proc, err := NewProcess(int32(cmd.Process.Pid))
+	require.NoError(t, err)
+
+	defer proc.Stop()
+
+	err = proc.SetMaxOpenFiles(64)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(cmd.Process.Pid) + "/limits")
+	require.NoError(t, err)
+
+	found := false
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		require.Equal(t, []string{"64", "64", "files"}, fields)
+		found = true
+	}
+
+	require.True(t, found)
+
+	n, err := proc.NumFDs()
+	require.NoError(t, err)
+	require.Greater(t, n, uint64(0))
+}
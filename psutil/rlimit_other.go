@@ -0,0 +1,12 @@
+//go:build !linux
+
+package psutil
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func (p *process) SetMaxOpenFiles(n uint64) error {
+	return fmt.Errorf("not supported on %s", runtime.GOOS)
+}
@@ -12,10 +12,11 @@ type ConfigIOCleanup struct {
 }
 
 type ConfigIO struct {
-	ID      string            `json:"id"`
-	Address string            `json:"address"`
-	Options []string          `json:"options"`
-	Cleanup []ConfigIOCleanup `json:"cleanup"`
+	ID       string            `json:"id"`
+	Address  string            `json:"address"`
+	Failover []string          `json:"failover,omitempty"` // Ordered list of backup addresses, only for inputs
+	Options  []string          `json:"options"`
+	Cleanup  []ConfigIOCleanup `json:"cleanup"`
 }
 
 func (io ConfigIO) Clone() ConfigIO {
@@ -24,6 +25,11 @@ func (io ConfigIO) Clone() ConfigIO {
 		Address: io.Address,
 	}
 
+	if io.Failover != nil {
+		clone.Failover = make([]string, len(io.Failover))
+		copy(clone.Failover, io.Failover)
+	}
+
 	clone.Options = make([]string, len(io.Options))
 	copy(clone.Options, io.Options)
 
@@ -33,34 +39,48 @@ func (io ConfigIO) Clone() ConfigIO {
 	return clone
 }
 
+type ConfigHook struct {
+	URL      string `json:"url"`             // URL to POST the event to
+	Timeout  uint64 `json:"timeout_seconds"` // seconds
+	Blocking bool   `json:"blocking"`        // Whether a failing hook prevents the process from starting, only for pre-start hooks
+}
+
 type Config struct {
-	ID             string     `json:"id"`
-	Reference      string     `json:"reference"`
-	FFVersion      string     `json:"ffversion"`
-	Input          []ConfigIO `json:"input"`
-	Output         []ConfigIO `json:"output"`
-	Options        []string   `json:"options"`
-	Reconnect      bool       `json:"reconnect"`
-	ReconnectDelay uint64     `json:"reconnect_delay_seconds"` // seconds
-	Autostart      bool       `json:"autostart"`
-	StaleTimeout   uint64     `json:"stale_timeout_seconds"` // seconds
-	LimitCPU       float64    `json:"limit_cpu_usage"`       // percent
-	LimitMemory    uint64     `json:"limit_memory_bytes"`    // bytes
-	LimitWaitFor   uint64     `json:"limit_waitfor_seconds"` // seconds
+	ID                string      `json:"id"`
+	Reference         string      `json:"reference"`
+	FFVersion         string      `json:"ffversion"`
+	Input             []ConfigIO  `json:"input"`
+	Output            []ConfigIO  `json:"output"`
+	Options           []string    `json:"options"`
+	Reconnect         bool        `json:"reconnect"`
+	ReconnectDelay    uint64      `json:"reconnect_delay_seconds"`               // seconds
+	ReconnectDelayMax uint64      `json:"reconnect_delay_max_seconds,omitempty"` // seconds
+	CrashLoopRestarts uint64      `json:"crashloop_restarts,omitempty"`
+	CrashLoopWindow   uint64      `json:"crashloop_window_seconds,omitempty"` // seconds
+	Autostart         bool        `json:"autostart"`
+	StaleTimeout      uint64      `json:"stale_timeout_seconds"` // seconds
+	LimitCPU          float64     `json:"limit_cpu_usage"`       // percent
+	LimitMemory       uint64      `json:"limit_memory_bytes"`    // bytes
+	LimitWaitFor      uint64      `json:"limit_waitfor_seconds"` // seconds
+	PreStart          *ConfigHook `json:"pre_start,omitempty"`
+	PostStop          *ConfigHook `json:"post_stop,omitempty"`
 }
 
 func (config *Config) Clone() *Config {
 	clone := &Config{
-		ID:             config.ID,
-		Reference:      config.Reference,
-		FFVersion:      config.FFVersion,
-		Reconnect:      config.Reconnect,
-		ReconnectDelay: config.ReconnectDelay,
-		Autostart:      config.Autostart,
-		StaleTimeout:   config.StaleTimeout,
-		LimitCPU:       config.LimitCPU,
-		LimitMemory:    config.LimitMemory,
-		LimitWaitFor:   config.LimitWaitFor,
+		ID:                config.ID,
+		Reference:         config.Reference,
+		FFVersion:         config.FFVersion,
+		Reconnect:         config.Reconnect,
+		ReconnectDelay:    config.ReconnectDelay,
+		ReconnectDelayMax: config.ReconnectDelayMax,
+		CrashLoopRestarts: config.CrashLoopRestarts,
+		CrashLoopWindow:   config.CrashLoopWindow,
+		Autostart:         config.Autostart,
+		StaleTimeout:      config.StaleTimeout,
+		LimitCPU:          config.LimitCPU,
+		LimitMemory:       config.LimitMemory,
+		LimitWaitFor:      config.LimitWaitFor,
 	}
 
 	clone.Input = make([]ConfigIO, len(config.Input))
@@ -76,6 +96,16 @@ func (config *Config) Clone() *Config {
 	clone.Options = make([]string, len(config.Options))
 	copy(clone.Options, config.Options)
 
+	if config.PreStart != nil {
+		hook := *config.PreStart
+		clone.PreStart = &hook
+	}
+
+	if config.PostStop != nil {
+		hook := *config.PostStop
+		clone.PostStop = &hook
+	}
+
 	return clone
 }
 
@@ -141,16 +171,56 @@ func (p *ProcessStates) Marshal(s process.States) {
 	p.Killed = s.Killed
 }
 
+type ProcessUsageCPU struct {
+	Current float64 // percent
+	Average float64 // percent
+	Max     float64 // percent
+	Limit   float64 // percent
+}
+
+type ProcessUsageMemory struct {
+	Current uint64  // bytes
+	Average float64 // bytes
+	Max     uint64  // bytes
+	Limit   uint64  // bytes
+}
+
+type ProcessUsage struct {
+	CPU    ProcessUsageCPU
+	Memory ProcessUsageMemory
+}
+
+type ProcessInputSource struct {
+	ID        string // ID of the input
+	Address   string // Currently active address of the input
+	Index     int    // Index of the active address, 0 is the primary address, >0 the failover addresses
+	Failovers uint64 // Number of switches to another address
+}
+
+type ProcessHookResult struct {
+	Time  int64  // Unix timestamp of the last execution, 0 if never executed
+	Error string // Error of the last execution, empty if it succeeded
+}
+
+type ProcessHooks struct {
+	PreStart ProcessHookResult
+	PostStop ProcessHookResult
+}
+
 type State struct {
-	Order     string        // Current order, e.g. "start", "stop"
-	State     string        // Current state, e.g. "running"
-	States    ProcessStates // Cumulated process states
-	Time      int64         // Unix timestamp of last status change
-	Duration  float64       // Runtime in seconds since last status change
-	Reconnect float64       // Seconds until next reconnect, negative if not reconnecting
-	LastLog   string        // Last recorded line from the process
-	Progress  Progress      // Progress data of the process
-	Memory    uint64        // Current memory consumption in bytes
-	CPU       float64       // Current CPU consumption in percent
-	Command   []string      // ffmpeg command line parameters
+	Order     string               // Current order, e.g. "start", "stop"
+	State     string               // Current state, e.g. "running"
+	States    ProcessStates        // Cumulated process states
+	Time      int64                // Unix timestamp of last status change
+	Duration  float64              // Runtime in seconds since last status change
+	Reconnect float64              // Seconds until next reconnect, negative if not reconnecting
+	CrashLoop bool                 // Whether the process has been stopped because it restarted too often
+	LastLog   string               // Last recorded line from the process
+	Progress  Progress             // Progress data of the process
+	Memory    uint64               // Current memory consumption in bytes
+	CPU       float64              // Current CPU consumption in percent
+	Resources ProcessUsage         // Current resource usage, include CPU and memory consumption
+	Sources   []ProcessInputSource // Active source of each input with failover addresses
+	Hooks     ProcessHooks         // Results of the last execution of the hooks
+	Command   []string             // ffmpeg command line parameters
 }
@@ -0,0 +1,100 @@
+package restream
+
+import (
+	"sync"
+
+	"github.com/datarhei/core/v16/restream/app"
+)
+
+// failover keeps track of the currently active address of all inputs
+// of a process that have a list of failover addresses.
+type failover struct {
+	inputs []failoverInput
+	lock   sync.Mutex
+}
+
+type failoverInput struct {
+	index     int      // Index of the input in the process config
+	id        string   // ID of the input
+	addresses []string // The primary address followed by the failover addresses
+	active    int      // Index of the currently active address
+	failovers uint64   // Number of switches to another address
+}
+
+// newFailover returns a failover for the given inputs. Inputs without any
+// failover addresses are ignored.
+func newFailover(inputs []app.ConfigIO) *failover {
+	f := &failover{}
+
+	for i, input := range inputs {
+		if len(input.Failover) == 0 {
+			continue
+		}
+
+		in := failoverInput{
+			index: i,
+			id:    input.ID,
+		}
+
+		in.addresses = append(in.addresses, input.Address)
+		in.addresses = append(in.addresses, input.Failover...)
+
+		f.inputs = append(f.inputs, in)
+	}
+
+	return f
+}
+
+// HasInputs returns whether there are any inputs with failover addresses.
+func (f *failover) HasInputs() bool {
+	return len(f.inputs) != 0
+}
+
+// Next switches all inputs to their next address. After the last failover
+// address, the primary address will be used again. It is not known which of
+// the inputs caused the process to fail, therefore all of them are switched.
+func (f *failover) Next() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for i, in := range f.inputs {
+		in.active = (in.active + 1) % len(in.addresses)
+		in.failovers++
+
+		f.inputs[i] = in
+	}
+}
+
+// Apply replaces the addresses of the inputs in the config with the
+// currently active addresses.
+func (f *failover) Apply(config *app.Config) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, in := range f.inputs {
+		if in.index >= len(config.Input) {
+			continue
+		}
+
+		config.Input[in.index].Address = in.addresses[in.active]
+	}
+}
+
+// Sources returns the currently active source of each input.
+func (f *failover) Sources() []app.ProcessInputSource {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	sources := []app.ProcessInputSource{}
+
+	for _, in := range f.inputs {
+		sources = append(sources, app.ProcessInputSource{
+			ID:        in.id,
+			Address:   in.addresses[in.active],
+			Index:     in.active,
+			Failovers: in.failovers,
+		})
+	}
+
+	return sources
+}
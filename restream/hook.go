@@ -0,0 +1,124 @@
+package restream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/datarhei/core/v16/restream/app"
+)
+
+// maxHookTimeout is the max. timeout of a hook in seconds.
+const maxHookTimeout = 60
+
+// hookEvent is the payload that is POSTed to the URL of a hook.
+type hookEvent struct {
+	Event     string `json:"event"`
+	ID        string `json:"id"`
+	Reference string `json:"reference"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// hook is a URL that gets notified about an event of a process.
+type hook struct {
+	url      string
+	blocking bool
+	client   *http.Client
+
+	result app.ProcessHookResult
+	lock   sync.Mutex
+}
+
+// newHook returns a new hook for the config. It returns nil if no hook is configured.
+func newHook(config *app.ConfigHook) *hook {
+	if config == nil || len(config.URL) == 0 {
+		return nil
+	}
+
+	timeout := 10 * time.Second
+	if config.Timeout > 0 {
+		timeout = time.Duration(min(config.Timeout, maxHookTimeout)) * time.Second
+	}
+
+	h := &hook{
+		url:      config.URL,
+		blocking: config.Blocking,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+
+	return h
+}
+
+// Run posts the event to the URL of the hook and records the result. The
+// hook failed if the request can't be sent or if the response doesn't
+// have a 2xx status code.
+func (h *hook) Run(event, id, reference string) error {
+	if h == nil {
+		return nil
+	}
+
+	err := h.post(hookEvent{
+		Event:     event,
+		ID:        id,
+		Reference: reference,
+		Timestamp: time.Now().Unix(),
+	})
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.result.Time = time.Now().Unix()
+	h.result.Error = ""
+
+	if err != nil {
+		h.result.Error = err.Error()
+	}
+
+	return err
+}
+
+func (h *hook) post(event hookEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%s hook failed: %w", event.Event, err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s hook failed: unexpected status code %d", event.Event, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// IsBlocking returns whether a failure of the hook should prevent the process from starting.
+func (h *hook) IsBlocking() bool {
+	if h == nil {
+		return false
+	}
+
+	return h.blocking
+}
+
+// Result returns the result of the last execution of the hook.
+func (h *hook) Result() app.ProcessHookResult {
+	if h == nil {
+		return app.ProcessHookResult{}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.result
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,7 +37,7 @@ type Restreamer interface {
 	Start()                                                      // Start all processes that have a "start" order
 	Stop()                                                       // Stop all running process but keep their "start" order
 	AddProcess(config *app.Config) error                         // Add a new process
-	GetProcessIDs(idpattern, refpattern string) []string         // Get a list of process IDs based on patterns for ID and reference
+	GetProcessIDs(idpattern, refpattern string) []string         // Get a sorted list of process IDs based on patterns for ID and reference
 	DeleteProcess(id string) error                               // Delete a process
 	UpdateProcess(id string, config *app.Config) error           // Update a process
 	StartProcess(id string) error                                // Start a process
@@ -45,6 +46,7 @@ type Restreamer interface {
 	ReloadProcess(id string) error                               // Reload a process
 	GetProcess(id string) (*app.Process, error)                  // Get a process
 	GetProcessState(id string) (*app.State, error)               // Get the state of a process
+	GetProcessLimiters() []ProcessLimiter                        // Get the limiters of all running processes
 	GetProcessLog(id string) (*app.Log, error)                   // Get the logs of a process
 	GetPlayout(id, inputid string) (string, error)               // Get the URL of the playout API for a process
 	Probe(id string) app.Probe                                   // Probe a process
@@ -69,6 +71,13 @@ type Config struct {
 	Logger       log.Logger
 }
 
+// ProcessLimiter is the limiter of a running process.
+type ProcessLimiter struct {
+	ID        string
+	Reference string
+	Limiter   process.Limiter
+}
+
 type task struct {
 	valid     bool
 	id        string // ID of the task/process
@@ -82,6 +91,116 @@ type task struct {
 	logger    log.Logger
 	usesDisk  bool // Whether this task uses the disk
 	metadata  map[string]interface{}
+	failover  *failover // Active addresses of the inputs with failover addresses
+	hooks     struct {
+		preStart *hook
+		postStop *hook
+	}
+}
+
+// onBeforeStart returns a callback for the process that runs the pre-start hook, or nil
+// if there's no pre-start hook. A failing hook only prevents the process from starting
+// if it is blocking.
+func (t *task) onBeforeStart() func() error {
+	hook := t.hooks.preStart
+	id, reference := t.id, t.reference
+	logger := t.logger
+
+	if hook == nil {
+		return nil
+	}
+
+	return func() error {
+		err := hook.Run("pre_start", id, reference)
+		if err == nil {
+			return nil
+		}
+
+		logger.Warn().WithError(err).Log("Pre-start hook failed")
+
+		if hook.IsBlocking() {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// onExit returns a callback for the process that runs the post-stop hook. The hook
+// runs in the background, such that stopping a process doesn't wait for it.
+func (t *task) onExit() func() {
+	hook := t.hooks.postStop
+	id, reference := t.id, t.reference
+	logger := t.logger
+
+	return func() {
+		if hook == nil {
+			return
+		}
+
+		go func() {
+			if err := hook.Run("post_stop", id, reference); err != nil {
+				logger.Warn().WithError(err).Log("Post-stop hook failed")
+			}
+		}()
+	}
+}
+
+// onGiveUp returns a callback for the process of a task that stopped restarting on its own,
+// e.g. because of a crash loop. The order of the task is changed to "stop", such that it
+// requires a manual start, also after a restart.
+func (r *restream) onGiveUp(t *task) func() {
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		// The task has been replaced or removed in the meantime
+		if r.tasks[t.id] != t {
+			return
+		}
+
+		// The process has been started again in the meantime
+		if t.process.Order != "start" || t.ffmpeg.Status().Order != "stop" {
+			return
+		}
+
+		t.process.Order = "stop"
+
+		r.nProc--
+
+		r.save()
+	}
+}
+
+// onArgs returns a callback for the process that switches the inputs with
+// failover addresses to their next address if the previous run failed or
+// ended without a stop order, e.g. because of the stale timeout.
+func (t *task) onArgs() func(args []string, state string, restart bool) []string {
+	config := t.config.Clone()
+	failover := t.failover
+	logger := t.logger
+
+	return func(args []string, state string, restart bool) []string {
+		if !failover.HasInputs() {
+			return args
+		}
+
+		if state == "failed" || restart {
+			failover.Next()
+
+			for _, s := range failover.Sources() {
+				logger.Warn().WithFields(log.Fields{
+					"input": s.ID,
+					"index": s.Index,
+				}).Log("Switching input address")
+			}
+		}
+
+		c := config.Clone()
+		failover.Apply(c)
+
+		return c.CreateCommand()
+	}
 }
 
 type restream struct {
@@ -349,18 +468,28 @@ func (r *restream) load() error {
 		}
 
 		t.command = t.config.CreateCommand()
+		t.failover = newFailover(t.config.Input)
+		t.hooks.preStart = newHook(t.config.PreStart)
+		t.hooks.postStop = newHook(t.config.PostStop)
 		t.parser = r.ffmpeg.NewProcessParser(t.logger, t.id, t.reference)
 
 		ffmpeg, err := r.ffmpeg.New(ffmpeg.ProcessConfig{
-			Reconnect:      t.config.Reconnect,
-			ReconnectDelay: time.Duration(t.config.ReconnectDelay) * time.Second,
-			StaleTimeout:   time.Duration(t.config.StaleTimeout) * time.Second,
-			LimitCPU:       t.config.LimitCPU,
-			LimitMemory:    t.config.LimitMemory,
-			LimitDuration:  time.Duration(t.config.LimitWaitFor) * time.Second,
-			Command:        t.command,
-			Parser:         t.parser,
-			Logger:         t.logger,
+			Reconnect:         t.config.Reconnect,
+			ReconnectDelay:    time.Duration(t.config.ReconnectDelay) * time.Second,
+			ReconnectDelayMax: time.Duration(t.config.ReconnectDelayMax) * time.Second,
+			CrashLoopRestarts: int(t.config.CrashLoopRestarts),
+			CrashLoopWindow:   time.Duration(t.config.CrashLoopWindow) * time.Second,
+			StaleTimeout:      time.Duration(t.config.StaleTimeout) * time.Second,
+			LimitCPU:          t.config.LimitCPU,
+			LimitMemory:       t.config.LimitMemory,
+			LimitDuration:     time.Duration(t.config.LimitWaitFor) * time.Second,
+			Command:           t.command,
+			Parser:            t.parser,
+			Logger:            t.logger,
+			OnArgs:            t.onArgs(),
+			OnBeforeStart:     t.onBeforeStart(),
+			OnGiveUp:          r.onGiveUp(t),
+			OnExit:            t.onExit(),
 		})
 		if err != nil {
 			return err
@@ -491,18 +620,28 @@ func (r *restream) createTask(config *app.Config) (*task, error) {
 	}
 
 	t.command = t.config.CreateCommand()
+	t.failover = newFailover(t.config.Input)
+	t.hooks.preStart = newHook(t.config.PreStart)
+	t.hooks.postStop = newHook(t.config.PostStop)
 	t.parser = r.ffmpeg.NewProcessParser(t.logger, t.id, t.reference)
 
 	ffmpeg, err := r.ffmpeg.New(ffmpeg.ProcessConfig{
-		Reconnect:      t.config.Reconnect,
-		ReconnectDelay: time.Duration(t.config.ReconnectDelay) * time.Second,
-		StaleTimeout:   time.Duration(t.config.StaleTimeout) * time.Second,
-		LimitCPU:       t.config.LimitCPU,
-		LimitMemory:    t.config.LimitMemory,
-		LimitDuration:  time.Duration(t.config.LimitWaitFor) * time.Second,
-		Command:        t.command,
-		Parser:         t.parser,
-		Logger:         t.logger,
+		Reconnect:         t.config.Reconnect,
+		ReconnectDelay:    time.Duration(t.config.ReconnectDelay) * time.Second,
+		ReconnectDelayMax: time.Duration(t.config.ReconnectDelayMax) * time.Second,
+		CrashLoopRestarts: int(t.config.CrashLoopRestarts),
+		CrashLoopWindow:   time.Duration(t.config.CrashLoopWindow) * time.Second,
+		StaleTimeout:      time.Duration(t.config.StaleTimeout) * time.Second,
+		LimitCPU:          t.config.LimitCPU,
+		LimitMemory:       t.config.LimitMemory,
+		LimitDuration:     time.Duration(t.config.LimitWaitFor) * time.Second,
+		Command:           t.command,
+		Parser:            t.parser,
+		Logger:            t.logger,
+		OnArgs:            t.onArgs(),
+		OnBeforeStart:     t.onBeforeStart(),
+		OnGiveUp:          r.onGiveUp(t),
+		OnExit:            t.onExit(),
 	})
 	if err != nil {
 		return nil, err
@@ -647,22 +786,21 @@ func (r *restream) validateConfig(config *app.Config) (bool, error) {
 			return false, fmt.Errorf("the address for input '#%s:%s' must not be empty", config.ID, io.ID)
 		}
 
-		if len(r.fs.diskfs) != 0 {
-			maxFails := 0
-			for _, fs := range r.fs.diskfs {
-				io.Address, err = r.validateInputAddress(io.Address, fs.Metadata("base"))
-				if err != nil {
-					maxFails++
-				}
-			}
+		io.Address, err = r.validateInputAddressFS(io.Address)
+		if err != nil {
+			return false, fmt.Errorf("the address for input '#%s:%s' (%s) is invalid: %w", config.ID, io.ID, io.Address, err)
+		}
 
-			if maxFails == len(r.fs.diskfs) {
-				return false, fmt.Errorf("the address for input '#%s:%s' (%s) is invalid: %w", config.ID, io.ID, io.Address, err)
+		for _, address := range io.Failover {
+			address = strings.TrimSpace(address)
+
+			if len(address) == 0 {
+				return false, fmt.Errorf("the failover addresses for input '#%s:%s' must not be empty", config.ID, io.ID)
 			}
-		} else {
-			io.Address, err = r.validateInputAddress(io.Address, "/")
+
+			address, err = r.validateInputAddressFS(address)
 			if err != nil {
-				return false, fmt.Errorf("the address for input '#%s:%s' (%s) is invalid: %w", config.ID, io.ID, io.Address, err)
+				return false, fmt.Errorf("the failover address for input '#%s:%s' (%s) is invalid: %w", config.ID, io.ID, address, err)
 			}
 		}
 	}
@@ -671,6 +809,29 @@ func (r *restream) validateConfig(config *app.Config) (bool, error) {
 		return false, fmt.Errorf("at least one output must be defined for the process '#%s'", config.ID)
 	}
 
+	if config.CrashLoopRestarts > 0 && config.CrashLoopWindow == 0 {
+		return false, fmt.Errorf("the crash loop detection of the process '#%s' requires a window", config.ID)
+	}
+
+	for name, hook := range map[string]*app.ConfigHook{"pre-start": config.PreStart, "post-stop": config.PostStop} {
+		if hook == nil || len(hook.URL) == 0 {
+			continue
+		}
+
+		u, err := url.Parse(hook.URL)
+		if err != nil {
+			return false, fmt.Errorf("the URL of the %s hook of the process '#%s' is invalid: %w", name, config.ID, err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return false, fmt.Errorf("the URL of the %s hook of the process '#%s' must be an http or https URL", name, config.ID)
+		}
+
+		if hook.Timeout > maxHookTimeout {
+			return false, fmt.Errorf("the timeout of the %s hook of the process '#%s' must not exceed %d seconds", name, config.ID, maxHookTimeout)
+		}
+	}
+
 	ids = map[string]bool{}
 	hasFiles := false
 
@@ -726,6 +887,30 @@ func (r *restream) validateConfig(config *app.Config) (bool, error) {
 	return hasFiles, nil
 }
 
+// validateInputAddressFS validates an input address against the base directories
+// of all disk filesystems. It is valid if it is valid for at least one of them.
+func (r *restream) validateInputAddressFS(address string) (string, error) {
+	if len(r.fs.diskfs) == 0 {
+		return r.validateInputAddress(address, "/")
+	}
+
+	var err error
+
+	maxFails := 0
+	for _, fs := range r.fs.diskfs {
+		address, err = r.validateInputAddress(address, fs.Metadata("base"))
+		if err != nil {
+			maxFails++
+		}
+	}
+
+	if maxFails == len(r.fs.diskfs) {
+		return address, err
+	}
+
+	return address, nil
+}
+
 func (r *restream) validateInputAddress(address, basedir string) (string, error) {
 	if ok := url.HasScheme(address); ok {
 		if err := url.Validate(address); err != nil {
@@ -821,6 +1006,15 @@ func (r *restream) resolveAddresses(tasks map[string]*task, config *app.Config)
 
 		input.Address = address
 
+		for j, address := range input.Failover {
+			address, err := r.resolveAddress(tasks, config.ID, address)
+			if err != nil {
+				return fmt.Errorf("reference error for failover address of '#%s:%s': %w", config.ID, input.ID, err)
+			}
+
+			input.Failover[j] = address
+		}
+
 		config.Input[i] = input
 	}
 
@@ -927,6 +1121,8 @@ func (r *restream) GetProcessIDs(idpattern, refpattern string) []string {
 			i++
 		}
 
+		sort.Strings(ids)
+
 		return ids
 	}
 
@@ -977,6 +1173,8 @@ func (r *restream) GetProcessIDs(idpattern, refpattern string) []string {
 		ids = append(ids, id)
 	}
 
+	sort.Strings(ids)
+
 	return ids
 }
 
@@ -1176,6 +1374,9 @@ func (r *restream) reloadProcess(id string) error {
 	}
 
 	t.command = t.config.CreateCommand()
+	t.failover = newFailover(t.config.Input)
+	t.hooks.preStart = newHook(t.config.PreStart)
+	t.hooks.postStop = newHook(t.config.PostStop)
 
 	order := "stop"
 	if t.process.Order == "start" {
@@ -1186,15 +1387,22 @@ func (r *restream) reloadProcess(id string) error {
 	t.parser = r.ffmpeg.NewProcessParser(t.logger, t.id, t.reference)
 
 	ffmpeg, err := r.ffmpeg.New(ffmpeg.ProcessConfig{
-		Reconnect:      t.config.Reconnect,
-		ReconnectDelay: time.Duration(t.config.ReconnectDelay) * time.Second,
-		StaleTimeout:   time.Duration(t.config.StaleTimeout) * time.Second,
-		LimitCPU:       t.config.LimitCPU,
-		LimitMemory:    t.config.LimitMemory,
-		LimitDuration:  time.Duration(t.config.LimitWaitFor) * time.Second,
-		Command:        t.command,
-		Parser:         t.parser,
-		Logger:         t.logger,
+		Reconnect:         t.config.Reconnect,
+		ReconnectDelay:    time.Duration(t.config.ReconnectDelay) * time.Second,
+		ReconnectDelayMax: time.Duration(t.config.ReconnectDelayMax) * time.Second,
+		CrashLoopRestarts: int(t.config.CrashLoopRestarts),
+		CrashLoopWindow:   time.Duration(t.config.CrashLoopWindow) * time.Second,
+		StaleTimeout:      time.Duration(t.config.StaleTimeout) * time.Second,
+		LimitCPU:          t.config.LimitCPU,
+		LimitMemory:       t.config.LimitMemory,
+		LimitDuration:     time.Duration(t.config.LimitWaitFor) * time.Second,
+		Command:           t.command,
+		Parser:            t.parser,
+		Logger:            t.logger,
+		OnArgs:            t.onArgs(),
+		OnBeforeStart:     t.onBeforeStart(),
+		OnGiveUp:          r.onGiveUp(t),
+		OnExit:            t.onExit(),
 	})
 	if err != nil {
 		return err
@@ -1210,6 +1418,35 @@ func (r *restream) reloadProcess(id string) error {
 	return nil
 }
 
+func (r *restream) GetProcessLimiters() []ProcessLimiter {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	limiters := []ProcessLimiter{}
+
+	for id, task := range r.tasks {
+		if !task.valid || task.ffmpeg == nil {
+			continue
+		}
+
+		if !task.ffmpeg.IsRunning() {
+			continue
+		}
+
+		limiters = append(limiters, ProcessLimiter{
+			ID:        id,
+			Reference: task.reference,
+			Limiter:   task.ffmpeg.Limiter(),
+		})
+	}
+
+	sort.Slice(limiters, func(i, j int) bool {
+		return limiters[i].ID < limiters[j].ID
+	})
+
+	return limiters
+}
+
 func (r *restream) GetProcessState(id string) (*app.State, error) {
 	state := &app.State{}
 
@@ -1233,13 +1470,29 @@ func (r *restream) GetProcessState(id string) (*app.State, error) {
 	state.Time = status.Time.Unix()
 	state.Memory = status.Memory.Current
 	state.CPU = status.CPU.Current
+	state.Resources.CPU = app.ProcessUsageCPU{
+		Current: status.CPU.Current,
+		Average: status.CPU.Average,
+		Max:     status.CPU.Max,
+		Limit:   status.CPU.Limit,
+	}
+	state.Resources.Memory = app.ProcessUsageMemory{
+		Current: status.Memory.Current,
+		Average: status.Memory.Average,
+		Max:     status.Memory.Max,
+		Limit:   status.Memory.Limit,
+	}
 	state.Duration = status.Duration.Round(10 * time.Millisecond).Seconds()
 	state.Reconnect = -1
+	state.CrashLoop = status.CrashLoop
+	state.Sources = task.failover.Sources()
+	state.Hooks.PreStart = task.hooks.preStart.Result()
+	state.Hooks.PostStop = task.hooks.postStop.Result()
 	state.Command = make([]string, len(task.command))
 	copy(state.Command, task.command)
 
 	if state.Order == "start" && !task.ffmpeg.IsRunning() && task.config.Reconnect {
-		state.Reconnect = float64(task.config.ReconnectDelay) - state.Duration
+		state.Reconnect = status.Reconnect.Seconds() - state.Duration
 
 		if state.Reconnect < 0 {
 			state.Reconnect = 0
@@ -1547,6 +1800,20 @@ func resolvePlaceholders(config *app.Config, r replace.Replacer) {
 		input.Address = r.Replace(input.Address, "rtmp", "", vars, config, "input")
 		input.Address = r.Replace(input.Address, "srt", "", vars, config, "input")
 
+		for j, address := range input.Failover {
+			// Replace any known placeholders
+			address = r.Replace(address, "inputid", input.ID, nil, nil, "input")
+			address = r.Replace(address, "processid", config.ID, nil, nil, "input")
+			address = r.Replace(address, "reference", config.Reference, nil, nil, "input")
+			address = r.Replace(address, "diskfs", "", vars, config, "input")
+			address = r.Replace(address, "memfs", "", vars, config, "input")
+			address = r.Replace(address, "fs:*", "", vars, config, "input")
+			address = r.Replace(address, "rtmp", "", vars, config, "input")
+			address = r.Replace(address, "srt", "", vars, config, "input")
+
+			input.Failover[j] = address
+		}
+
 		for j, option := range input.Options {
 			// Replace any known placeholders
 			option = r.Replace(option, "inputid", input.ID, nil, nil, "input")
@@ -1,7 +1,10 @@
 package restream
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -299,6 +302,28 @@ func TestGetProcess(t *testing.T) {
 	require.ElementsMatch(t, []string{"bar_bbb_2"}, list)
 }
 
+func TestGetProcessIDsOrder(t *testing.T) {
+	rs, err := getDummyRestreamer(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	for _, id := range []string{"foo_ccc_3", "bar_bbb_2", "foo_aaa_1", "bar_ddd_4"} {
+		process := getDummyProcess()
+		process.ID = id
+		process.Reference = id
+
+		err := rs.AddProcess(process)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 10; i++ {
+		list := rs.GetProcessIDs("", "")
+		require.Equal(t, []string{"bar_bbb_2", "bar_ddd_4", "foo_aaa_1", "foo_ccc_3"}, list)
+
+		list = rs.GetProcessIDs("foo_*", "")
+		require.Equal(t, []string{"foo_aaa_1", "foo_ccc_3"}, list)
+	}
+}
+
 func TestStartProcess(t *testing.T) {
 	rs, err := getDummyRestreamer(nil, nil, nil, nil)
 	require.NoError(t, err)
@@ -577,6 +602,311 @@ func TestAddressReference(t *testing.T) {
 	require.Equal(t, nil, err, "should resolve reference")
 }
 
+func TestInputFailover(t *testing.T) {
+	rs, err := getDummyRestreamer(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	process := getDummyProcess()
+	process.Input[0].Failover = []string{
+		"testsrc=size=640x360:rate=25",
+		"  ",
+	}
+
+	err = rs.AddProcess(process)
+	require.Error(t, err, "empty failover address must not be allowed")
+
+	process.Input[0].Failover = []string{
+		"testsrc=size=640x360:rate=25",
+		"testsrc=size=320x180:rate=25",
+	}
+
+	err = rs.AddProcess(process)
+	require.NoError(t, err)
+
+	state, err := rs.GetProcessState(process.ID)
+	require.NoError(t, err)
+	require.Equal(t, []app.ProcessInputSource{
+		{ID: "in", Address: "testsrc=size=1280x720:rate=25", Index: 0, Failovers: 0},
+	}, state.Sources)
+
+	task := rs.(*restream).tasks[process.ID]
+	onArgs := task.onArgs()
+
+	args := onArgs(task.command, "finished", false)
+	require.Contains(t, args, "testsrc=size=1280x720:rate=25")
+
+	args = onArgs(task.command, "failed", false)
+	require.Contains(t, args, "testsrc=size=640x360:rate=25")
+	require.NotContains(t, args, "testsrc=size=1280x720:rate=25")
+
+	// The process has been stopped and started again
+	args = onArgs(task.command, "killed", false)
+	require.Contains(t, args, "testsrc=size=640x360:rate=25")
+
+	// The process ended by itself or has been stopped by the stale timeout
+	args = onArgs(task.command, "finished", true)
+	require.Contains(t, args, "testsrc=size=320x180:rate=25")
+
+	args = onArgs(task.command, "killed", true)
+	require.Contains(t, args, "testsrc=size=1280x720:rate=25")
+
+	args = onArgs(task.command, "failed", true)
+	require.Contains(t, args, "testsrc=size=640x360:rate=25")
+
+	state, err = rs.GetProcessState(process.ID)
+	require.NoError(t, err)
+	require.Equal(t, []app.ProcessInputSource{
+		{ID: "in", Address: "testsrc=size=640x360:rate=25", Index: 1, Failovers: 4},
+	}, state.Sources)
+}
+
+func TestInputFailoverStale(t *testing.T) {
+	rs, err := getDummyRestreamer(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	// The primary address doesn't deliver any data
+	process := getDummyProcess()
+	process.Input[0].Address = "stale"
+	process.Input[0].Failover = []string{
+		"testsrc=size=640x360:rate=25",
+	}
+	process.ReconnectDelay = 0
+	process.StaleTimeout = 1
+
+	err = rs.AddProcess(process)
+	require.NoError(t, err)
+
+	err = rs.StartProcess(process.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		state, err := rs.GetProcessState(process.ID)
+		if err != nil {
+			return false
+		}
+
+		return state.Sources[0].Index == 1 && state.State == "running"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	state, err := rs.GetProcessState(process.ID)
+	require.NoError(t, err)
+	require.Equal(t, []app.ProcessInputSource{
+		{ID: "in", Address: "testsrc=size=640x360:rate=25", Index: 1, Failovers: 1},
+	}, state.Sources)
+
+	err = rs.StopProcess(process.ID)
+	require.NoError(t, err)
+}
+
+func TestHooks(t *testing.T) {
+	events := make(chan hookEvent, 10)
+	status := http.StatusInternalServerError
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := hookEvent{}
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	rs, err := getDummyRestreamer(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	process := getDummyProcess()
+	process.Reconnect = false
+	process.PreStart = &app.ConfigHook{
+		URL:      "ftp://example.com",
+		Blocking: true,
+	}
+
+	err = rs.AddProcess(process)
+	require.Error(t, err, "only http and https hooks are allowed")
+
+	process.PreStart.URL = server.URL
+	process.PostStop = &app.ConfigHook{
+		URL: server.URL,
+	}
+
+	err = rs.AddProcess(process)
+	require.NoError(t, err)
+
+	err = rs.StartProcess(process.ID)
+	require.NoError(t, err)
+
+	event := <-events
+	require.Equal(t, "pre_start", event.Event)
+	require.Equal(t, process.ID, event.ID)
+
+	require.Eventually(t, func() bool {
+		state, err := rs.GetProcessState(process.ID)
+		require.NoError(t, err)
+		return state.State == "failed"
+	}, 2*time.Second, 10*time.Millisecond, "a failing blocking pre-start hook must prevent the start")
+
+	state, err := rs.GetProcessState(process.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, state.Hooks.PreStart.Error)
+
+	rs.StopProcess(process.ID)
+
+	status = http.StatusOK
+
+	err = rs.StartProcess(process.ID)
+	require.NoError(t, err)
+
+	event = <-events
+	require.Equal(t, "pre_start", event.Event)
+
+	require.Eventually(t, func() bool {
+		state, err := rs.GetProcessState(process.ID)
+		require.NoError(t, err)
+		return state.State == "running"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	state, err = rs.GetProcessState(process.ID)
+	require.NoError(t, err)
+	require.Empty(t, state.Hooks.PreStart.Error)
+	require.NotZero(t, state.Hooks.PreStart.Time)
+
+	rs.StopProcess(process.ID)
+
+	event = <-events
+	require.Equal(t, "post_stop", event.Event)
+}
+
+func TestHooksDontBlock(t *testing.T) {
+	release := make(chan struct{})
+	events := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := hookEvent{}
+		json.NewDecoder(r.Body).Decode(&event)
+
+		<-release
+
+		events <- event.Event
+	}))
+	defer server.Close()
+	defer close(release)
+
+	rs, err := getDummyRestreamer(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	process := getDummyProcess()
+	process.Reconnect = false
+	process.PreStart = &app.ConfigHook{
+		URL:     server.URL,
+		Timeout: maxHookTimeout + 1,
+	}
+
+	err = rs.AddProcess(process)
+	require.Error(t, err, "the timeout of a hook is limited")
+
+	process.PreStart.Timeout = 5
+	process.PostStop = &app.ConfigHook{
+		URL:     server.URL,
+		Timeout: 5,
+	}
+
+	err = rs.AddProcess(process)
+	require.NoError(t, err)
+
+	// The pending pre-start hook doesn't block the restreamer
+	err = rs.StartProcess(process.ID)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		rs.GetProcessState(process.ID)
+		rs.GetProcessIDs("", "")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "the restreamer is blocked by the pre-start hook")
+	}
+
+	release <- struct{}{}
+	require.Equal(t, "pre_start", <-events)
+
+	require.Eventually(t, func() bool {
+		state, err := rs.GetProcessState(process.ID)
+		require.NoError(t, err)
+		return state.State == "running"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Stopping doesn't wait for the post-stop hook
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		rs.StopProcess(process.ID)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "stopping the process is blocked by the post-stop hook")
+	}
+
+	release <- struct{}{}
+	require.Equal(t, "post_stop", <-events)
+}
+
+func TestCrashLoop(t *testing.T) {
+	rs, err := getDummyRestreamer(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	process := getDummyProcess()
+	process.ReconnectDelay = 0
+	process.CrashLoopRestarts = 2
+
+	err = rs.AddProcess(process)
+	require.Error(t, err, "a crash loop detection without window must be rejected")
+
+	process.CrashLoopWindow = 10
+
+	// The helper program exits immediately if the last argument is an option
+	process.Output[0].Address = "-crash"
+
+	err = rs.AddProcess(process)
+	require.NoError(t, err)
+
+	err = rs.StartProcess(process.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		p, err := rs.GetProcess(process.ID)
+		require.NoError(t, err)
+		return p.Order == "stop"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	state, err := rs.GetProcessState(process.ID)
+	require.NoError(t, err)
+	require.True(t, state.CrashLoop)
+	require.Equal(t, "stop", state.Order)
+
+	require.Equal(t, int64(0), rs.(*restream).nProc)
+
+	// A manual start is required
+	err = rs.StartProcess(process.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), rs.(*restream).nProc)
+
+	err = rs.StopProcess(process.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(0), rs.(*restream).nProc)
+}
+
 func TestConfigValidation(t *testing.T) {
 	rsi, err := getDummyRestreamer(nil, nil, nil, nil)
 	require.NoError(t, err)
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/datarhei/core/v16/log"
+	corenet "github.com/datarhei/core/v16/net"
 	"github.com/datarhei/core/v16/session"
 
 	"github.com/datarhei/joy4/av/avutil"
@@ -376,7 +377,7 @@ func (s *server) handlePlay(conn *rtmp.Conn) {
 			return
 		}
 
-		if s.token != token {
+		if !corenet.VerifyToken(s.token, token) {
 			s.log(log.Lwarn, "PLAY", "FORBIDDEN", path, "invalid streamkey ("+token+")", client)
 			return
 		}
@@ -466,7 +467,7 @@ func (s *server) handlePublish(conn *rtmp.Conn) {
 			return
 		}
 
-		if s.token != token {
+		if !corenet.VerifyToken(s.token, token) {
 			s.log(log.Lwarn, "PUBLISH", "FORBIDDEN", path, "invalid streamkey ("+token+")", client)
 			return
 		}
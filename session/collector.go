@@ -7,7 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/datarhei/core/v16/io/fs"
 	"github.com/datarhei/core/v16/log"
 	"github.com/datarhei/core/v16/net"
 
@@ -123,6 +122,14 @@ type Collector interface {
 	// IsSessionsExceeded return whether the maximum number of session have been exceeded.
 	IsSessionsExceeded() bool
 
+	// SetMaxSessions sets the maximum number of sessions. A value of 0 means no limit.
+	// Already existing sessions are not affected.
+	SetMaxSessions(max uint64)
+
+	// SetMaxEgressBitrate sets the maximum egress bitrate in bit/s. A value of 0 means
+	// no limit.
+	SetMaxEgressBitrate(bitrate uint64)
+
 	// IsKnowsession returns whether a session with the given id exists.
 	IsKnownSession(id string) bool
 
@@ -242,8 +249,7 @@ type collector struct {
 
 	persist struct {
 		enable   bool
-		fs       fs.Filesystem
-		path     string
+		store    Store
 		interval time.Duration
 		done     context.CancelFunc
 	}
@@ -260,6 +266,7 @@ type collector struct {
 		history   sync.RWMutex
 		persist   sync.Mutex
 		companion sync.RWMutex
+		limits    sync.RWMutex
 	}
 
 	startOnce sync.Once
@@ -284,7 +291,7 @@ func NewCollector(config CollectorConfig) Collector {
 	return collector
 }
 
-func newCollector(id string, persistFS fs.Filesystem, logger log.Logger, config CollectorConfig) (*collector, error) {
+func newCollector(id string, store Store, logger log.Logger, config CollectorConfig) (*collector, error) {
 	c := &collector{
 		maxRxBitrate:    float64(config.MaxRxBitrate),
 		maxTxBitrate:    float64(config.MaxTxBitrate),
@@ -378,12 +385,11 @@ func newCollector(id string, persistFS fs.Filesystem, logger log.Logger, config
 
 	c.history.Sessions = make(map[string]totals)
 
-	c.persist.enable = persistFS != nil
-	c.persist.fs = persistFS
-	c.persist.path = "/" + id + ".json"
+	c.persist.enable = store != nil
+	c.persist.store = store
 	c.persist.interval = config.PersistInterval
 
-	c.loadHistory(c.persist.fs, c.persist.path, &c.history)
+	c.loadHistory(c.persist.store, &c.history)
 
 	c.stopOnce.Do(func() {})
 
@@ -433,7 +439,7 @@ func (c *collector) Persist() {
 	c.lock.history.RLock()
 	defer c.lock.history.RUnlock()
 
-	c.saveHistory(c.persist.fs, c.persist.path, &c.history)
+	c.saveHistory(c.persist.store, &c.history)
 }
 
 func (c *collector) persister(ctx context.Context, interval time.Duration) {
@@ -450,21 +456,25 @@ func (c *collector) persister(ctx context.Context, interval time.Duration) {
 	}
 }
 
-func (c *collector) loadHistory(fs fs.Filesystem, path string, data *history) {
-	if fs == nil {
+func (c *collector) loadHistory(store Store, data *history) {
+	if store == nil {
 		return
 	}
 
-	c.logger.WithComponent("SessionStore").WithFields(log.Fields{
-		"base": fs.Metadata("base"),
-		"path": path,
-	}).Debug().Log("Loading history")
+	logger := c.logger.WithComponent("SessionStore").WithField("id", c.id)
+
+	logger.Debug().Log("Loading history")
 
 	c.lock.persist.Lock()
 	defer c.lock.persist.Unlock()
 
-	jsondata, err := fs.ReadFile(path)
+	jsondata, err := store.Load(c.id)
 	if err != nil {
+		logger.Error().WithError(err).Log("Loading history failed")
+		return
+	}
+
+	if len(jsondata) == 0 {
 		return
 	}
 
@@ -473,15 +483,14 @@ func (c *collector) loadHistory(fs fs.Filesystem, path string, data *history) {
 	}
 }
 
-func (c *collector) saveHistory(fs fs.Filesystem, path string, data *history) {
-	if fs == nil {
+func (c *collector) saveHistory(store Store, data *history) {
+	if store == nil {
 		return
 	}
 
-	c.logger.WithComponent("SessionStore").WithFields(log.Fields{
-		"base": fs.Metadata("base"),
-		"path": path,
-	}).Debug().Log("Storing history")
+	logger := c.logger.WithComponent("SessionStore").WithField("id", c.id)
+
+	logger.Debug().Log("Storing history")
 
 	c.lock.persist.Lock()
 	defer c.lock.persist.Unlock()
@@ -491,8 +500,8 @@ func (c *collector) saveHistory(fs fs.Filesystem, path string, data *history) {
 		return
 	}
 
-	_, _, err = fs.WriteFileSafe(path, jsondata)
-	if err != nil {
+	if err = store.Save(c.id, jsondata); err != nil {
+		logger.Error().WithError(err).Log("Storing history failed")
 		return
 	}
 }
@@ -644,11 +653,13 @@ func (c *collector) IsIngressBitrateExceeded() bool {
 }
 
 func (c *collector) IsEgressBitrateExceeded() bool {
-	if c.maxTxBitrate <= 0 {
+	maxTxBitrate := c.MaxEgressBitrate()
+
+	if maxTxBitrate <= 0 {
 		return false
 	}
 
-	if c.EgressBitrate() > c.maxTxBitrate {
+	if c.EgressBitrate() > maxTxBitrate {
 		return true
 	}
 
@@ -656,17 +667,35 @@ func (c *collector) IsEgressBitrateExceeded() bool {
 }
 
 func (c *collector) IsSessionsExceeded() bool {
-	if c.maxSessions <= 0 {
+	c.lock.limits.RLock()
+	maxSessions := c.maxSessions
+	c.lock.limits.RUnlock()
+
+	if maxSessions <= 0 {
 		return false
 	}
 
-	if c.Sessions() >= c.maxSessions {
+	if c.Sessions() >= maxSessions {
 		return true
 	}
 
 	return false
 }
 
+func (c *collector) SetMaxSessions(max uint64) {
+	c.lock.limits.Lock()
+	defer c.lock.limits.Unlock()
+
+	c.maxSessions = max
+}
+
+func (c *collector) SetMaxEgressBitrate(bitrate uint64) {
+	c.lock.limits.Lock()
+	defer c.lock.limits.Unlock()
+
+	c.maxTxBitrate = float64(bitrate)
+}
+
 func (c *collector) IngressBitrate() float64 {
 	return c.rxBitrate.Average(averageWindow)
 }
@@ -680,6 +709,9 @@ func (c *collector) MaxIngressBitrate() float64 {
 }
 
 func (c *collector) MaxEgressBitrate() float64 {
+	c.lock.limits.RLock()
+	defer c.lock.limits.RUnlock()
+
 	return c.maxTxBitrate
 }
 
@@ -762,11 +794,13 @@ func (c *collector) Sessions() uint64 {
 }
 
 func (c *collector) Summary() Summary {
+	c.lock.limits.RLock()
 	summary := Summary{
 		MaxSessions:  c.maxSessions,
 		MaxRxBitrate: c.maxRxBitrate,
 		MaxTxBitrate: c.maxTxBitrate,
 	}
+	c.lock.limits.RUnlock()
 
 	summary.CurrentSessions = c.currentActiveSessions
 	summary.CurrentRxBitrate = c.IngressBitrate()
@@ -937,6 +971,8 @@ func (n *nullCollector) TopEgressBitrate() float64
 func (n *nullCollector) IsIngressBitrateExceeded() bool                           { return false }
 func (n *nullCollector) IsEgressBitrateExceeded() bool                            { return false }
 func (n *nullCollector) IsSessionsExceeded() bool                                 { return false }
+func (n *nullCollector) SetMaxSessions(max uint64)                                {}
+func (n *nullCollector) SetMaxEgressBitrate(bitrate uint64)                       {}
 func (n *nullCollector) IsKnownSession(id string) bool                            { return false }
 func (n *nullCollector) IsCollectableIP(ip string) bool                           { return true }
 func (n *nullCollector) Summary() Summary                                         { return Summary{} }
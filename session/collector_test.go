@@ -142,3 +142,38 @@ func TestNbSessions(t *testing.T) {
 	nsessions = c.Sessions()
 	require.Equal(t, uint64(0), nsessions)
 }
+
+func TestSetLimits(t *testing.T) {
+	c, err := newCollector("", nil, nil, CollectorConfig{
+		InactiveTimeout: time.Hour,
+		SessionTimeout:  time.Hour,
+		MaxSessions:     3,
+		MaxTxBitrate:    1024,
+	})
+	require.Equal(t, nil, err)
+
+	c.RegisterAndActivate("foo", "", "", "")
+	c.RegisterAndActivate("bar", "", "", "")
+
+	require.Equal(t, false, c.IsSessionsExceeded())
+	require.Equal(t, float64(1024), c.MaxEgressBitrate())
+
+	c.SetMaxSessions(2)
+	c.SetMaxEgressBitrate(2048)
+
+	require.Equal(t, true, c.IsSessionsExceeded())
+	require.Equal(t, float64(2048), c.MaxEgressBitrate())
+
+	require.Equal(t, true, c.IsKnownSession("foo"))
+	require.Equal(t, true, c.IsKnownSession("bar"))
+
+	summary := c.Summary()
+	require.Equal(t, uint64(2), summary.MaxSessions)
+	require.Equal(t, float64(2048), summary.MaxTxBitrate)
+
+	c.SetMaxSessions(0)
+
+	require.Equal(t, false, c.IsSessionsExceeded())
+
+	c.Stop()
+}
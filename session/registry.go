@@ -15,6 +15,10 @@ type Config struct {
 	// history will not be persisted.
 	PersistFS fs.Filesystem
 
+	// Store is a backend for persisting the session history. If it is nil, the history will be
+	// persisted to PersistFS. If PersistFS is nil as well, the history will be kept in memory.
+	Store Store
+
 	// Logger is an instance of a logger. If it is nil, no logs
 	// will be written.
 	Logger log.Logger
@@ -51,21 +55,34 @@ type Registry interface {
 
 type registry struct {
 	collector map[string]*collector
-	persistFS fs.Filesystem
+	store     Store
 	logger    log.Logger
 
 	lock sync.Mutex
 }
 
 // New returns a new registry for collectors that implement the Registry interface. The error
-// is non-nil if the PersistDir from the config can't be created.
+// is non-nil if the store for the history can't be created.
 func New(conf Config) (Registry, error) {
 	r := &registry{
 		collector: make(map[string]*collector),
-		persistFS: conf.PersistFS,
+		store:     conf.Store,
 		logger:    conf.Logger,
 	}
 
+	if r.store == nil {
+		if conf.PersistFS != nil {
+			store, err := NewFSStore(conf.PersistFS)
+			if err != nil {
+				return nil, err
+			}
+
+			r.store = store
+		} else {
+			r.store = NewMemoryStore()
+		}
+	}
+
 	if r.logger == nil {
 		r.logger = log.New("Session")
 	}
@@ -91,7 +108,7 @@ func (r *registry) Register(id string, conf CollectorConfig) (Collector, error)
 		return nil, fmt.Errorf("a collector with the ID '%s' already exists", id)
 	}
 
-	m, err := newCollector(id, r.persistFS, r.logger, conf)
+	m, err := newCollector(id, r.store, r.logger, conf)
 	if err != nil {
 		return nil, err
 	}
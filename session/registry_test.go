@@ -3,6 +3,8 @@ package session
 import (
 	"testing"
 
+	"github.com/datarhei/core/v16/io/fs"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -95,3 +97,39 @@ func TestUnregisterAll(t *testing.T) {
 	c = r.Collectors()
 	require.Equal(t, []string{}, c)
 }
+
+func TestPersistHistory(t *testing.T) {
+	memfs, err := fs.NewMemFilesystem(fs.MemConfig{})
+	require.NoError(t, err)
+
+	store, err := NewFSStore(memfs)
+	require.NoError(t, err)
+
+	for _, s := range []Store{NewMemoryStore(), store} {
+		r, err := New(Config{
+			Store: s,
+		})
+		require.NoError(t, err)
+
+		c, err := r.Register("foobar", CollectorConfig{})
+		require.NoError(t, err)
+
+		c.RegisterAndActivate("foo", "ref", "location", "peer")
+		c.Ingress("foo", 1024)
+
+		err = r.Unregister("foobar")
+		require.NoError(t, err)
+
+		r, err = New(Config{
+			Store: s,
+		})
+		require.NoError(t, err)
+
+		c, err = r.Register("foobar", CollectorConfig{})
+		require.NoError(t, err)
+
+		summary := c.Summary()
+		require.Equal(t, uint64(1), summary.Summary.TotalSessions)
+		require.Equal(t, uint64(1024), summary.Summary.TotalRxBytes)
+	}
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/datarhei/core/v16/io/fs"
+)
+
+// Store is a backend for persisting the session history of collectors. The
+// history is passed as an opaque blob, such that a store doesn't need to know
+// about its structure. A store can be shared between multiple registries, e.g.
+// in order to aggregate the history of several instances.
+type Store interface {
+	// Load returns the stored history of the collector with the ID. If there's no
+	// stored history, nil data and a nil error are returned.
+	Load(id string) ([]byte, error)
+
+	// Save stores the history of the collector with the ID.
+	Save(id string, data []byte) error
+}
+
+type memoryStore struct {
+	data map[string][]byte
+	lock sync.RWMutex
+}
+
+// NewMemoryStore returns a store that keeps the history in memory. The history
+// will survive re-registering a collector, but not a restart.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		data: make(map[string][]byte),
+	}
+}
+
+func (s *memoryStore) Load(id string) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	data, ok := s.data[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+func (s *memoryStore) Save(id string, data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data[id] = append([]byte(nil), data...)
+
+	return nil
+}
+
+type fsStore struct {
+	fs fs.Filesystem
+}
+
+// NewFSStore returns a store that writes the history of each collector into a
+// JSON file in the root of the filesystem.
+func NewFSStore(filesystem fs.Filesystem) (Store, error) {
+	if filesystem == nil {
+		return nil, fmt.Errorf("no filesystem provided")
+	}
+
+	return &fsStore{
+		fs: filesystem,
+	}, nil
+}
+
+func (s *fsStore) path(id string) string {
+	return "/" + id + ".json"
+}
+
+func (s *fsStore) Load(id string) ([]byte, error) {
+	path := s.path(id)
+
+	if _, err := s.fs.Stat(path); err != nil {
+		return nil, nil
+	}
+
+	return s.fs.ReadFile(path)
+}
+
+func (s *fsStore) Save(id string, data []byte) error {
+	_, _, err := s.fs.WriteFileSafe(s.path(id), data)
+
+	return err
+}
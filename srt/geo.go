@@ -0,0 +1,68 @@
+package srt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/datarhei/core/v16/net"
+)
+
+// GeoInfo is the geographical information about an IP address.
+type GeoInfo struct {
+	Country      string // ISO 3166-1 alpha-2 country code
+	ASN          uint   // Autonomous system number
+	Organization string // Organization of the autonomous system
+}
+
+// GeoResolver resolves an IP address to its geographical information,
+// e.g. by looking it up in a GeoIP database.
+type GeoResolver interface {
+	Resolve(ip string) (GeoInfo, error)
+}
+
+// GeoResolverFunc is an adapter to allow the use of an ordinary function
+// as a GeoResolver.
+type GeoResolverFunc func(ip string) (GeoInfo, error)
+
+func (f GeoResolverFunc) Resolve(ip string) (GeoInfo, error) {
+	return f(ip)
+}
+
+// sessionExtra returns the extra information for the session of a client. It
+// contains the anonymized IP of the client, in the same format as the HLS
+// sessions, followed by the geographical information if a resolver is given.
+// The resolver is called with the full IP.
+func sessionExtra(resolver GeoResolver, ip string) string {
+	anonymizedIP, _ := net.AnonymizeIPString(ip)
+
+	extra := "[" + anonymizedIP + "]"
+
+	if resolver == nil {
+		return extra
+	}
+
+	info, err := resolver.Resolve(ip)
+	if err != nil {
+		return extra
+	}
+
+	fields := []string{}
+
+	if len(info.Country) != 0 {
+		fields = append(fields, "country:"+info.Country)
+	}
+
+	if info.ASN != 0 {
+		fields = append(fields, "asn:"+strconv.FormatUint(uint64(info.ASN), 10))
+	}
+
+	if len(info.Organization) != 0 {
+		fields = append(fields, "org:"+info.Organization)
+	}
+
+	if len(fields) == 0 {
+		return extra
+	}
+
+	return extra + " " + strings.Join(fields, ",")
+}
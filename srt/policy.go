@@ -0,0 +1,108 @@
+package srt
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/datarhei/core/v16/glob"
+
+	srt "github.com/datarhei/gosrt"
+	"github.com/datarhei/gosrt/packet"
+)
+
+// Policy holds the limits for a single connection.
+type Policy struct {
+	// MaxBitrate is the max. bitrate in bit/s a publisher is allowed to
+	// send. 0 means no limit.
+	MaxBitrate float64
+
+	// MaxSubscribers is the max. number of subscribers on the channel
+	// of a publisher. 0 means no limit.
+	MaxSubscribers int
+
+	// Resources is a list of glob patterns of the resources the connection
+	// is allowed to publish or subscribe to. An empty list allows all
+	// resources.
+	Resources []string
+}
+
+// AuthFunc authenticates a connection request for a resource with the token
+// from the stream ID. It returns the policy for the connection, or an error
+// if the connection has to be rejected.
+type AuthFunc func(resource, token string, publish bool, client net.Addr) (Policy, error)
+
+// Credentials are the passphrase and the token that are required for a resource.
+// Empty values mean that no passphrase or no token is required.
+type Credentials struct {
+	Passphrase string
+	Token      string
+}
+
+// CredentialsFunc returns the credentials for a resource. If there are no specific
+// credentials for the resource, ok is false.
+type CredentialsFunc func(resource string) (credentials Credentials, ok bool)
+
+// IsAllowed returns whether the policy allows the resource.
+func (p Policy) IsAllowed(resource string) bool {
+	if len(p.Resources) == 0 {
+		return true
+	}
+
+	for _, pattern := range p.Resources {
+		if ok, _ := glob.Match(pattern, resource, '/'); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bitrateConn is a connection that fails reading packets as soon as the
+// bitrate of the received data exceeds a limit. The bitrate is measured
+// over a window, beginning with the first second of the window.
+type bitrateConn struct {
+	srt.Conn
+
+	maxBitrate float64
+	window     time.Duration
+
+	start time.Time
+	bytes uint64
+}
+
+func newBitrateConn(conn srt.Conn, maxBitrate float64) *bitrateConn {
+	return &bitrateConn{
+		Conn:       conn,
+		maxBitrate: maxBitrate,
+		window:     5 * time.Second,
+		start:      time.Now(),
+	}
+}
+
+func (c *bitrateConn) ReadPacket() (packet.Packet, error) {
+	p, err := c.Conn.ReadPacket()
+	if err != nil {
+		return p, err
+	}
+
+	c.bytes += p.Len()
+
+	elapsed := time.Since(c.start)
+	if elapsed < time.Second {
+		return p, nil
+	}
+
+	bitrate := float64(c.bytes*8) / elapsed.Seconds()
+	if bitrate > c.maxBitrate {
+		p.Decommission()
+		return nil, fmt.Errorf("bitrate of %.0f bit/s exceeds the limit of %.0f bit/s", bitrate, c.maxBitrate)
+	}
+
+	if elapsed >= c.window {
+		c.start = time.Now()
+		c.bytes = 0
+	}
+
+	return p, nil
+}
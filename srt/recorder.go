@@ -0,0 +1,183 @@
+package srt
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/datarhei/core/v16/io/fs"
+	"github.com/datarhei/core/v16/log"
+
+	srt "github.com/datarhei/gosrt"
+	"github.com/datarhei/gosrt/packet"
+)
+
+// RecordConfig is the configuration for recording published streams.
+type RecordConfig struct {
+	// Filesystem is where the recordings are written to. Recording is
+	// disabled if no filesystem is given.
+	Filesystem fs.Filesystem
+
+	// Pattern is a regular expression. A published stream is recorded
+	// automatically if its resource matches the pattern. An empty pattern
+	// doesn't record any stream automatically. Optional.
+	Pattern string
+
+	// Path is the directory on the filesystem for the recordings. Each
+	// recording is written to "<Path>/<resource>/<start>_<sequence>.ts".
+	Path string
+
+	// MaxSize is the max. size of a file in bytes before a new file is
+	// started. 0 means unlimited.
+	MaxSize int64
+
+	// MaxDuration is the max. duration of a file before a new file is
+	// started. 0 means unlimited.
+	MaxDuration time.Duration
+}
+
+var recordResourceReplacer = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// recorder writes the MPEG-TS data of a published stream to files on a filesystem. It
+// subscribes to the pubsub of a channel like any other subscriber. If writing the files
+// is too slow, the pubsub drops packets for the recorder, but not for the other
+// subscribers.
+type recorder struct {
+	config   RecordConfig
+	dir      string
+	start    time.Time
+	logger   log.Logger
+	socketId uint32
+	exited   chan struct{}
+
+	seq          int
+	writer       *io.PipeWriter
+	done         chan error
+	size         int64
+	segmentStart time.Time
+	stopped      bool
+	lock         sync.Mutex
+}
+
+func newRecorder(config RecordConfig, resource string, logger log.Logger) *recorder {
+	r := &recorder{
+		config:   config,
+		dir:      path.Join("/", config.Path, recordResourceReplacer.ReplaceAllString(resource, "_")),
+		start:    time.Now(),
+		logger:   logger,
+		socketId: nextSocketId(),
+		exited:   make(chan struct{}),
+	}
+
+	if r.logger == nil {
+		r.logger = log.New("")
+	}
+
+	return r
+}
+
+// WritePacket writes the data of a packet to the current file. A new file is started
+// if the current file exceeds the configured size or duration.
+func (r *recorder) WritePacket(p packet.Packet) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.stopped {
+		return io.ErrClosedPipe
+	}
+
+	if r.writer == nil {
+		r.open()
+	}
+
+	n, err := r.writer.Write(p.Data())
+	r.size += int64(n)
+
+	if err != nil {
+		r.close()
+		r.stopped = true
+		return err
+	}
+
+	if (r.config.MaxSize > 0 && r.size >= r.config.MaxSize) || (r.config.MaxDuration > 0 && time.Since(r.segmentStart) >= r.config.MaxDuration) {
+		r.close()
+	}
+
+	return nil
+}
+
+// open starts a new file. The data is streamed to the filesystem in the background.
+func (r *recorder) open() {
+	r.seq++
+
+	name := path.Join(r.dir, fmt.Sprintf("%s_%04d.ts", r.start.UTC().Format("20060102-150405"), r.seq))
+
+	reader, writer := io.Pipe()
+
+	r.writer = writer
+	r.done = make(chan error, 1)
+	r.size = 0
+	r.segmentStart = time.Now()
+
+	go func(done chan<- error) {
+		_, _, err := r.config.Filesystem.WriteFileReader(name, reader)
+
+		// Unblock the writer in case the filesystem didn't read all data
+		if err != nil {
+			reader.CloseWithError(err)
+		} else {
+			reader.Close()
+		}
+
+		done <- err
+	}(r.done)
+
+	r.logger.Debug().WithField("path", name).Log("Recording started")
+}
+
+// close finishes the current file and waits until it is written to the filesystem.
+func (r *recorder) close() {
+	if r.writer == nil {
+		return
+	}
+
+	r.writer.Close()
+
+	if err := <-r.done; err != nil {
+		r.logger.Warn().WithError(err).Log("Writing recording failed")
+	}
+
+	r.writer = nil
+}
+
+// Close stops the recording and finishes the current file.
+func (r *recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.close()
+	r.stopped = true
+
+	return nil
+}
+
+// The recorder is only used for writing packets, all other methods
+// of the srt.Conn interface are no-ops.
+
+func (r *recorder) Read(p []byte) (int, error)         { return 0, io.EOF }
+func (r *recorder) ReadPacket() (packet.Packet, error) { return nil, io.EOF }
+func (r *recorder) Write(p []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (r *recorder) LocalAddr() net.Addr                { return nil }
+func (r *recorder) RemoteAddr() net.Addr               { return nil }
+func (r *recorder) SetDeadline(t time.Time) error      { return nil }
+func (r *recorder) SetReadDeadline(t time.Time) error  { return nil }
+func (r *recorder) SetWriteDeadline(t time.Time) error { return nil }
+func (r *recorder) SocketId() uint32                   { return r.socketId }
+func (r *recorder) PeerSocketId() uint32               { return r.socketId }
+func (r *recorder) StreamId() string                   { return "" }
+func (r *recorder) Stats(s *srt.Statistics)            {}
+func (r *recorder) Version() uint32                    { return 5 }
@@ -0,0 +1,174 @@
+package srt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/datarhei/core/v16/log"
+
+	srt "github.com/datarhei/gosrt"
+	"github.com/datarhei/gosrt/packet"
+	"github.com/datarhei/joy4/format/rtmp"
+	"github.com/datarhei/joy4/format/ts"
+)
+
+// RelayConfig is the configuration for relaying published streams to an RTMP server.
+type RelayConfig struct {
+	// Targets maps a resource to the RTMP URL the stream is relayed to while it
+	// is published. The stream is remuxed from MPEG-TS to FLV without transcoding,
+	// i.e. it can only be relayed if its codecs are supported by RTMP. Optional.
+	Targets map[string]string
+
+	// Timeout is the time for connecting to the RTMP server and for writing
+	// to it. Optional. Default 10 seconds.
+	Timeout time.Duration
+}
+
+// validateRelayTarget checks whether the target is a RTMP URL.
+func validateRelayTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "rtmp" {
+		return fmt.Errorf("unsupported scheme '%s', only rtmp is supported", u.Scheme)
+	}
+
+	if len(u.Host) == 0 {
+		return fmt.Errorf("missing host")
+	}
+
+	return nil
+}
+
+// relay pushes the stream of a channel to a RTMP server. It subscribes to the pubsub of a
+// channel like any other subscriber. If the RTMP server is too slow, the pubsub drops packets
+// for the relay, but not for the other subscribers.
+type relay struct {
+	target   string
+	timeout  time.Duration
+	logger   log.Logger
+	socketId uint32
+
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	done   chan struct{}
+	err    error
+}
+
+func newRelay(target string, timeout time.Duration, logger log.Logger) *relay {
+	r := &relay{
+		target:   target,
+		timeout:  timeout,
+		logger:   logger,
+		socketId: nextSocketId(),
+		done:     make(chan struct{}),
+	}
+
+	if r.timeout <= 0 {
+		r.timeout = 10 * time.Second
+	}
+
+	if r.logger == nil {
+		r.logger = log.New("")
+	}
+
+	r.reader, r.writer = io.Pipe()
+
+	go func() {
+		defer close(r.done)
+
+		r.err = r.relay()
+
+		// Unblock the writer in case the relay didn't read all data
+		if r.err != nil {
+			r.reader.CloseWithError(r.err)
+		} else {
+			r.reader.Close()
+		}
+	}()
+
+	return r
+}
+
+// relay connects to the RTMP server and remuxes the MPEG-TS data from the
+// pipe until the pipe is closed.
+func (r *relay) relay() error {
+	conn, err := rtmp.DialTimeout(r.target, r.timeout, rtmp.DialOptions{})
+	if err != nil {
+		return fmt.Errorf("connecting to RTMP server failed: %w", err)
+	}
+
+	defer conn.Close()
+
+	conn.SetIdleTimeout(r.timeout)
+
+	demuxer := ts.NewDemuxer(r.reader)
+
+	streams, err := demuxer.Streams()
+	if err != nil {
+		return fmt.Errorf("probing stream failed: %w", err)
+	}
+
+	if err := conn.WriteHeader(streams); err != nil {
+		return fmt.Errorf("publishing to RTMP server failed: %w", err)
+	}
+
+	r.logger.Debug().Log("Relay started")
+
+	for {
+		pkt, err := demuxer.ReadPacket()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+
+			return fmt.Errorf("reading stream failed: %w", err)
+		}
+
+		if err := conn.WritePacket(pkt); err != nil {
+			return fmt.Errorf("writing to RTMP server failed: %w", err)
+		}
+	}
+
+	return conn.WriteTrailer()
+}
+
+// WritePacket passes the data of a packet on to the relay.
+func (r *relay) WritePacket(p packet.Packet) error {
+	_, err := r.writer.Write(p.Data())
+
+	return err
+}
+
+// Close stops the relay and waits until the connection to the RTMP server is closed.
+// It returns the error why the relay failed, if any.
+func (r *relay) Close() error {
+	r.writer.Close()
+
+	<-r.done
+
+	return r.err
+}
+
+// The relay is only used for writing packets, all other methods
+// of the srt.Conn interface are no-ops.
+
+func (r *relay) Read(p []byte) (int, error)         { return 0, io.EOF }
+func (r *relay) ReadPacket() (packet.Packet, error) { return nil, io.EOF }
+func (r *relay) Write(p []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (r *relay) LocalAddr() net.Addr                { return nil }
+func (r *relay) RemoteAddr() net.Addr               { return nil }
+func (r *relay) SetDeadline(t time.Time) error      { return nil }
+func (r *relay) SetReadDeadline(t time.Time) error  { return nil }
+func (r *relay) SetWriteDeadline(t time.Time) error { return nil }
+func (r *relay) SocketId() uint32                   { return r.socketId }
+func (r *relay) PeerSocketId() uint32               { return r.socketId }
+func (r *relay) StreamId() string                   { return "" }
+func (r *relay) Stats(s *srt.Statistics)            {}
+func (r *relay) Version() uint32                    { return 5 }
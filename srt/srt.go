@@ -3,14 +3,19 @@ package srt
 import (
 	"container/ring"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/datarhei/core/v16/log"
+	corenet "github.com/datarhei/core/v16/net"
 	"github.com/datarhei/core/v16/session"
 	srt "github.com/datarhei/gosrt"
 )
@@ -78,15 +83,29 @@ func (c *client) Close() {
 	c.cancel()
 }
 
+// The pubsub of a channel tells its subscribers apart by their socket ID. The
+// subscribers that are not SRT connections, i.e. recorders and relays, each get
+// their own socket ID from this counter. It counts down from the top of the ID
+// space, while the listener assigns the IDs of the connections counting up.
+var internalSocketId atomic.Uint32
+
+func nextSocketId() uint32 {
+	return internalSocketId.Add(^uint32(0))
+}
+
 // channel represents a stream that is sent to the server
 type channel struct {
 	pubsub    srt.PubSub
 	collector session.Collector
 	path      string
 
-	publisher  *client
-	subscriber map[string]*client
-	lock       sync.RWMutex
+	publisher       *client
+	subscriber      map[string]*client
+	maxSubscribers  int
+	peakSubscribers int
+	recorder        *recorder
+	relay           *relay
+	lock            sync.RWMutex
 }
 
 func newChannel(conn srt.Conn, resource string, collector session.Collector) *channel {
@@ -115,23 +134,57 @@ func (ch *channel) Close() {
 
 	ch.publisher.Close()
 	ch.publisher = nil
+
+	ch.lock.Lock()
+	recorder := ch.recorder
+	ch.recorder = nil
+	relay := ch.relay
+	ch.relay = nil
+	ch.lock.Unlock()
+
+	if recorder != nil {
+		recorder.Close()
+	}
+
+	if relay != nil {
+		relay.Close()
+	}
 }
 
-func (ch *channel) AddSubscriber(conn srt.Conn, resource string) string {
+// AddSubscriber adds a subscriber to the channel. It returns an error if
+// the max. number of subscribers is already reached.
+func (ch *channel) AddSubscriber(conn srt.Conn, resource string) (string, error) {
 	addr := conn.RemoteAddr().String()
 	ip, _, _ := net.SplitHostPort(addr)
 
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	if ch.maxSubscribers > 0 && len(ch.subscriber) >= ch.maxSubscribers {
+		return "", fmt.Errorf("max. number of %d subscribers reached", ch.maxSubscribers)
+	}
+
 	client := newClient(conn, addr, ch.collector)
 
 	if ch.collector.IsCollectableIP(ip) {
 		ch.collector.RegisterAndActivate(addr, resource, "play:"+resource, addr)
 	}
 
-	ch.lock.Lock()
 	ch.subscriber[addr] = client
-	ch.lock.Unlock()
 
-	return addr
+	if len(ch.subscriber) > ch.peakSubscribers {
+		ch.peakSubscribers = len(ch.subscriber)
+	}
+
+	return addr, nil
+}
+
+// IsFull returns whether the max. number of subscribers is reached.
+func (ch *channel) IsFull() bool {
+	ch.lock.RLock()
+	defer ch.lock.RUnlock()
+
+	return ch.maxSubscribers > 0 && len(ch.subscriber) >= ch.maxSubscribers
 }
 
 func (ch *channel) RemoveSubscriber(id string) {
@@ -158,12 +211,58 @@ type Config struct {
 
 	Passphrase string
 
+	// ConnectionTimeout is the time a connection has to be established
+	// including the handshake. Optional. By default the timeout of the
+	// SRT library is used.
+	ConnectionTimeout time.Duration
+
+	// PeerIdleTimeout is the time after which a connection will be closed
+	// if no data has been received from the peer. Optional. By default the
+	// timeout of the SRT library is used.
+	PeerIdleTimeout time.Duration
+
+	// AllowCIDR is a list of IP ranges in CIDR notation of the clients that
+	// are allowed to publish or subscribe. An empty list allows all clients.
+	// Optional.
+	AllowCIDR []string
+
+	// DenyCIDR is a list of IP ranges in CIDR notation of the clients that
+	// are not allowed to publish or subscribe. It takes precedence over the
+	// AllowCIDR list. Optional.
+	DenyCIDR []string
+
+	// MaxSubscribersPerChannel is the max. number of subscribers of a
+	// channel. A lower limit from the policy of the publisher takes
+	// precedence. 0 means unlimited.
+	MaxSubscribersPerChannel int
+
+	// Credentials is called for each connection request in order to get the
+	// passphrase and token for the requested resource. If it doesn't return any
+	// credentials, the Passphrase and Token from above are used. Optional.
+	Credentials CredentialsFunc
+
+	// Auth is called for each connection request in order to authenticate
+	// it. If set, the Token will not be checked. It returns the policy with
+	// the limits for the connection. Optional.
+	Auth AuthFunc
+
 	// Logger. Optional.
 	Logger log.Logger
 
 	Collector session.Collector
 
+	// GeoResolver is used to add the geographical information of a client
+	// to its session in the collector. Optional.
+	GeoResolver GeoResolver
+
 	SRTLogTopics []string
+
+	// Record is the configuration for recording published streams. Optional.
+	Record RecordConfig
+
+	// Relay is the configuration for relaying published streams to a RTMP
+	// server. Optional.
+	Relay RelayConfig
 }
 
 // Server represents a SRT server
@@ -174,25 +273,68 @@ type Server interface {
 	// Close stops the RTMP server and closes all connections
 	Close()
 
+	// Drain stops accepting new connections and waits for all subscribers to
+	// disconnect before it closes the server. A channel is closed as soon as it
+	// has no subscribers anymore. If the context is done before, the server is
+	// closed anyways and the error of the context is returned.
+	Drain(ctx context.Context) error
+
 	// Channels return a list of currently publishing streams
 	Channels() Channels
+
+	// ChannelSummaries returns the summaries of all currently publishing streams
+	ChannelSummaries() []ChannelSummary
+
+	// CloseConnection closes the publishing or subscribing connection with
+	// the given socket ID. Closing a publisher will end the whole channel.
+	CloseConnection(socketId uint32) error
+
+	// CloseChannel closes the publisher and all subscribers of a resource.
+	CloseChannel(resource string) error
+
+	// StartRecording starts recording the stream of a resource.
+	StartRecording(resource string) error
+
+	// StopRecording stops recording the stream of a resource.
+	StopRecording(resource string) error
 }
 
 // server implements the Server interface
 type server struct {
-	addr       string
-	token      string
-	passphrase string
+	addr        string
+	token       string
+	passphrase  string
+	credentials CredentialsFunc
+	auth        AuthFunc
+
+	maxSubscribersPerChannel int
+
+	iplimiter corenet.IPLimiter
 
 	collector session.Collector
+	geo       GeoResolver
+
+	record        RecordConfig
+	recordPattern *regexp.Regexp
+
+	relay RelayConfig
 
 	server srt.Server
 
 	// Map of publishing channels and a lock to serialize
-	// access to the map.
+	// access to the map. No new connections are accepted
+	// while draining.
 	channels map[string]*channel
+	draining bool
 	lock     sync.RWMutex
 
+	// Map of the policies of accepted publish requests until the
+	// connection is handled. Policies of requests that never turn
+	// into a connection expire after the connection timeout.
+	policies      map[string]policyEntry
+	policiesLock  sync.Mutex
+	policyTimeout time.Duration
+
 	logger log.Logger
 
 	srtlogger       srt.Logger
@@ -203,11 +345,18 @@ type server struct {
 
 func New(config Config) (Server, error) {
 	s := &server{
-		addr:       config.Addr,
-		token:      config.Token,
-		passphrase: config.Passphrase,
-		collector:  config.Collector,
-		logger:     config.Logger,
+		addr:        config.Addr,
+		token:       config.Token,
+		passphrase:  config.Passphrase,
+		credentials: config.Credentials,
+
+		maxSubscribersPerChannel: config.MaxSubscribersPerChannel,
+		auth:                     config.Auth,
+		collector:                config.Collector,
+		geo:                      config.GeoResolver,
+		record:                   config.Record,
+		relay:                    config.Relay,
+		logger:                   config.Logger,
 	}
 
 	if s.collector == nil {
@@ -225,14 +374,48 @@ func New(config Config) (Server, error) {
 	s.srtlogLock.Unlock()
 
 	s.channels = make(map[string]*channel)
+	s.policies = make(map[string]policyEntry)
+
+	iplimiter, err := corenet.NewIPLimiter(config.DenyCIDR, config.AllowCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP ranges: %w", err)
+	}
+
+	s.iplimiter = iplimiter
+
+	if s.record.Filesystem != nil && len(s.record.Pattern) != 0 {
+		s.recordPattern, err = regexp.Compile(s.record.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid record pattern: %w", err)
+		}
+	}
+
+	for resource, target := range s.relay.Targets {
+		if err := validateRelayTarget(target); err != nil {
+			return nil, fmt.Errorf("invalid relay target for resource '%s': %w", resource, err)
+		}
+	}
 
 	srtconfig := srt.DefaultConfig()
 
 	srtconfig.Passphrase = config.Passphrase
 	srtconfig.Logger = s.srtlogger
 
+	if config.ConnectionTimeout > 0 {
+		srtconfig.ConnectionTimeout = config.ConnectionTimeout
+	}
+
+	if config.PeerIdleTimeout > 0 {
+		srtconfig.PeerIdleTimeout = config.PeerIdleTimeout
+	}
+
+	if err := srtconfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SRT configuration: %w", err)
+	}
+
 	s.server.Addr = config.Addr
 	s.server.Config = &srtconfig
+	s.policyTimeout = srtconfig.ConnectionTimeout
 	s.server.HandleConnect = s.handleConnect
 	s.server.HandlePublish = s.handlePublish
 	s.server.HandleSubscribe = s.handleSubscribe
@@ -252,7 +435,246 @@ func (s *server) ListenAndServe() error {
 func (s *server) Close() {
 	s.server.Shutdown()
 
-	s.srtloggerCancel()
+	if s.srtloggerCancel != nil {
+		s.srtloggerCancel()
+	}
+}
+
+func (s *server) Drain(ctx context.Context) error {
+	s.lock.Lock()
+	s.draining = true
+	s.lock.Unlock()
+
+	defer s.Close()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	closed := map[*channel]struct{}{}
+
+	for {
+		if s.drainChannels(closed) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// drainChannels closes all channels without subscribers that are not yet in closed and
+// returns the number of channels that are still active.
+func (s *server) drainChannels(closed map[*channel]struct{}) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for resource, ch := range s.channels {
+		if _, ok := closed[ch]; ok {
+			continue
+		}
+
+		ch.lock.RLock()
+		idle := len(ch.subscriber) == 0 && ch.publisher != nil
+		ch.lock.RUnlock()
+
+		if idle {
+			s.closeChannel(resource, ch)
+			closed[ch] = struct{}{}
+		}
+	}
+
+	return len(s.channels)
+}
+
+// isDraining returns whether the server is draining.
+func (s *server) isDraining() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.draining
+}
+
+func (s *server) CloseConnection(socketId uint32) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for resource, ch := range s.channels {
+		if ch.publisher.conn.SocketId() == socketId {
+			s.closeChannel(resource, ch)
+			return nil
+		}
+
+		ch.lock.RLock()
+		for id, c := range ch.subscriber {
+			if c.conn.SocketId() != socketId {
+				continue
+			}
+
+			ch.lock.RUnlock()
+
+			c.conn.Close()
+			ch.RemoveSubscriber(id)
+			s.collector.Unregister(id)
+
+			s.log("CLOSE", "SUBSCRIBER", resource, "connection closed", c.conn.RemoteAddr())
+
+			return nil
+		}
+		ch.lock.RUnlock()
+	}
+
+	return fmt.Errorf("connection with socket ID %d not found", socketId)
+}
+
+func (s *server) CloseChannel(resource string) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ch := s.channels[resource]
+	if ch == nil {
+		return fmt.Errorf("channel for resource '%s' not found", resource)
+	}
+
+	s.closeChannel(resource, ch)
+
+	return nil
+}
+
+// closeChannel closes the publishing connection of a channel. This will end
+// the publisher in handlePublish and subsequently all subscribers will receive
+// an EOF. The channel itself will be removed by handlePublish.
+func (s *server) closeChannel(resource string, ch *channel) {
+	conn := ch.publisher.conn
+
+	conn.Close()
+	s.collector.Unregister(resource)
+
+	s.log("CLOSE", "PUBLISHER", resource, "connection closed", conn.RemoteAddr())
+}
+
+func (s *server) StartRecording(resource string) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ch := s.channels[resource]
+	if ch == nil {
+		return fmt.Errorf("channel for resource '%s' not found", resource)
+	}
+
+	return s.startRecording(resource, ch)
+}
+
+// startRecording subscribes a recorder to the channel. The recording ends when it is
+// stopped, writing fails, or the publisher disconnects.
+func (s *server) startRecording(resource string, ch *channel) error {
+	if s.record.Filesystem == nil {
+		return fmt.Errorf("recording is not enabled")
+	}
+
+	ch.lock.Lock()
+	if ch.publisher == nil {
+		ch.lock.Unlock()
+		return fmt.Errorf("channel for resource '%s' not found", resource)
+	}
+
+	if ch.recorder != nil {
+		ch.lock.Unlock()
+		return fmt.Errorf("channel for resource '%s' is already being recorded", resource)
+	}
+
+	client := ch.publisher.conn.RemoteAddr()
+
+	rec := newRecorder(s.record, resource, s.logger.WithField("resource", resource))
+	ch.recorder = rec
+	ch.lock.Unlock()
+
+	s.log("RECORD", "START", resource, "", client)
+
+	go func() {
+		defer close(rec.exited)
+
+		err := ch.pubsub.Subscribe(rec)
+
+		ch.lock.Lock()
+		if ch.recorder == rec {
+			ch.recorder = nil
+		}
+		ch.lock.Unlock()
+
+		rec.Close()
+
+		if errors.Is(err, io.ErrClosedPipe) {
+			err = nil
+		}
+
+		s.logStop("RECORD", resource, err, client)
+	}()
+
+	return nil
+}
+
+// startRelay subscribes a relay to the channel. The relay ends when the publisher
+// disconnects or the RTMP server fails.
+func (s *server) startRelay(resource string, ch *channel, target string) {
+	client := ch.publisher.conn.RemoteAddr()
+
+	r := newRelay(target, s.relay.Timeout, s.logger.WithField("resource", resource))
+
+	ch.lock.Lock()
+	ch.relay = r
+	ch.lock.Unlock()
+
+	s.log("RELAY", "START", resource, "", client)
+
+	go func() {
+		err := ch.pubsub.Subscribe(r)
+
+		ch.lock.Lock()
+		if ch.relay == r {
+			ch.relay = nil
+		}
+		ch.lock.Unlock()
+
+		if rerr := r.Close(); rerr != nil {
+			err = rerr
+		}
+
+		if errors.Is(err, io.ErrClosedPipe) {
+			err = nil
+		}
+
+		s.logStop("RELAY", resource, err, client)
+	}()
+}
+
+func (s *server) StopRecording(resource string) error {
+	s.lock.RLock()
+	ch := s.channels[resource]
+	s.lock.RUnlock()
+
+	if ch == nil {
+		return fmt.Errorf("channel for resource '%s' not found", resource)
+	}
+
+	ch.lock.Lock()
+	rec := ch.recorder
+	ch.recorder = nil
+	ch.lock.Unlock()
+
+	if rec == nil {
+		return fmt.Errorf("channel for resource '%s' is not being recorded", resource)
+	}
+
+	rec.Close()
+
+	// Wait until the recorder is unsubscribed from the pubsub. It will notice
+	// that it has been closed with the next packet or when the publisher leaves.
+	<-rec.exited
+
+	return nil
 }
 
 type Log struct {
@@ -272,6 +694,56 @@ type Channels struct {
 	Log         map[string][]Log
 }
 
+// ChannelSummary is the summary of a publishing stream and its subscribers
+type ChannelSummary struct {
+	Resource        string
+	RxBytes         uint64        // Bytes received from the publisher
+	TxBytes         uint64        // Bytes sent to all current subscribers
+	Subscribers     int           // Current number of subscribers
+	PeakSubscribers int           // Max. number of subscribers at the same time
+	Uptime          time.Duration // Time since the publisher connected
+}
+
+func (s *server) ChannelSummaries() []ChannelSummary {
+	summaries := []ChannelSummary{}
+
+	stats := &srt.Statistics{}
+
+	s.lock.RLock()
+	for resource, ch := range s.channels {
+		summary := ChannelSummary{
+			Resource: resource,
+		}
+
+		ch.lock.RLock()
+		if ch.publisher != nil {
+			ch.publisher.conn.Stats(stats)
+
+			summary.RxBytes = stats.Accumulated.ByteRecv
+			summary.Uptime = time.Since(ch.publisher.createdAt)
+		}
+
+		for _, c := range ch.subscriber {
+			c.conn.Stats(stats)
+
+			summary.TxBytes += stats.Accumulated.ByteSent
+		}
+
+		summary.Subscribers = len(ch.subscriber)
+		summary.PeakSubscribers = ch.peakSubscribers
+		ch.lock.RUnlock()
+
+		summaries = append(summaries, summary)
+	}
+	s.lock.RUnlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Resource < summaries[j].Resource
+	})
+
+	return summaries
+}
+
 func (s *server) Channels() Channels {
 	st := Channels{
 		Publisher:   map[string]uint32{},
@@ -293,6 +765,7 @@ func (s *server) Channels() Channels {
 			Log:   map[string][]Log{},
 		}
 
+		ch.lock.RLock()
 		for _, c := range ch.subscriber {
 			socketId := c.conn.SocketId()
 			st.Subscriber[id] = append(st.Subscriber[id], socketId)
@@ -305,6 +778,7 @@ func (s *server) Channels() Channels {
 				Log:   map[string][]Log{},
 			}
 		}
+		ch.lock.RUnlock()
 	}
 	s.lock.RUnlock()
 
@@ -363,6 +837,35 @@ func (s *server) log(handler, action, resource, message string, client net.Addr)
 	}).Log(message)
 }
 
+// logStop logs the end of a publication or subscription together with the reason
+// why it ended. The reason is "eof" if the connection or the publisher closed
+// regularly, or "error" if reading or writing failed.
+func (s *server) logStop(handler, resource string, err error, client net.Addr) {
+	reason := stopReason(err)
+
+	logger := s.logger.Info().WithFields(log.Fields{
+		"handler":  handler,
+		"status":   "STOP",
+		"resource": resource,
+		"client":   client.String(),
+		"reason":   reason,
+	})
+
+	if reason == "error" {
+		logger = logger.WithError(err)
+	}
+
+	logger.Log("")
+}
+
+func stopReason(err error) string {
+	if err == nil || errors.Is(err, io.EOF) {
+		return "eof"
+	}
+
+	return "error"
+}
+
 type streamInfo struct {
 	mode     string
 	resource string
@@ -471,6 +974,19 @@ func (s *server) handleConnect(req srt.ConnRequest) srt.ConnType {
 	var si streamInfo
 	var err error
 
+	passphrase, token := s.passphrase, s.token
+
+	if s.isDraining() {
+		s.log("CONNECT", "DRAINING", "", "server is shutting down", client)
+		return srt.REJECT
+	}
+
+	ip, _, _ := net.SplitHostPort(client.String())
+	if !s.iplimiter.IsAllowed(ip) {
+		s.log("CONNECT", "FORBIDDEN", "", "client IP not allowed", client)
+		return srt.REJECT
+	}
+
 	if req.Version() == 4 {
 		si.mode = "publish"
 		si.resource = client.String()
@@ -490,13 +1006,15 @@ func (s *server) handleConnect(req srt.ConnRequest) srt.ConnType {
 			return srt.REJECT
 		}
 
-		if len(s.passphrase) != 0 {
+		passphrase, token = s.resourceCredentials(si.resource)
+
+		if len(passphrase) != 0 {
 			if !req.IsEncrypted() {
 				s.log("CONNECT", "FORBIDDEN", si.resource, "connection has to be encrypted", client)
 				return srt.REJECT
 			}
 
-			if err := req.SetPassphrase(s.passphrase); err != nil {
+			if err := req.SetPassphrase(passphrase); err != nil {
 				s.log("CONNECT", "FORBIDDEN", si.resource, err.Error(), client)
 				return srt.REJECT
 			}
@@ -517,8 +1035,21 @@ func (s *server) handleConnect(req srt.ConnRequest) srt.ConnType {
 		return srt.REJECT
 	}
 
-	// Check the token
-	if len(s.token) != 0 && s.token != si.token {
+	policy := Policy{}
+
+	if s.auth != nil {
+		policy, err = s.auth(si.resource, si.token, mode == srt.PUBLISH, client)
+		if err != nil {
+			s.log("CONNECT", "FORBIDDEN", si.resource, err.Error(), client)
+			return srt.REJECT
+		}
+
+		if !policy.IsAllowed(si.resource) {
+			s.log("CONNECT", "FORBIDDEN", si.resource, "resource not allowed", client)
+			return srt.REJECT
+		}
+	} else if len(token) != 0 && !corenet.VerifyToken(token, si.token) {
+		// Check the token
 		if len(si.token) == 0 {
 			s.log("CONNECT", "FORBIDDEN", si.resource, "token required", client)
 		} else {
@@ -541,9 +1072,98 @@ func (s *server) handleConnect(req srt.ConnRequest) srt.ConnType {
 		return srt.REJECT
 	}
 
+	if mode == srt.SUBSCRIBE && ch.IsFull() {
+		s.log("CONNECT", "FULL", si.resource, "max. number of subscribers reached", client)
+		return srt.REJECT
+	}
+
+	// Only the policy of a publisher applies to the connection
+	if mode == srt.PUBLISH {
+		s.setPolicy(client, streamId, policy)
+	}
+
 	return mode
 }
 
+type policyEntry struct {
+	policy  Policy
+	created time.Time
+}
+
+func policyKey(client net.Addr, streamId string) string {
+	return client.String() + "|" + streamId
+}
+
+// setPolicy stores the policy for a connection until it is handled. Expired
+// policies of requests that didn't turn into a connection are removed.
+func (s *server) setPolicy(client net.Addr, streamId string, policy Policy) {
+	s.policiesLock.Lock()
+	defer s.policiesLock.Unlock()
+
+	now := time.Now()
+
+	for key, e := range s.policies {
+		if now.Sub(e.created) > s.policyTimeout {
+			delete(s.policies, key)
+		}
+	}
+
+	s.policies[policyKey(client, streamId)] = policyEntry{
+		policy:  policy,
+		created: now,
+	}
+}
+
+// takePolicy returns the policy for a connection and removes it. If no policy
+// is found, an empty policy without any limits is returned.
+func (s *server) takePolicy(client net.Addr, streamId string) Policy {
+	s.policiesLock.Lock()
+	defer s.policiesLock.Unlock()
+
+	key := policyKey(client, streamId)
+
+	e := s.policies[key]
+	delete(s.policies, key)
+
+	return e.policy
+}
+
+// maxSubscribers returns the max. number of subscribers for the channel of a
+// publisher with the policy. The lower limit of the policy and the server
+// wide limit applies. 0 means unlimited.
+func (s *server) maxSubscribers(policy Policy) int {
+	max := s.maxSubscribersPerChannel
+
+	if policy.MaxSubscribers > 0 && (max == 0 || policy.MaxSubscribers < max) {
+		max = policy.MaxSubscribers
+	}
+
+	return max
+}
+
+// resourceCredentials returns the passphrase and the token for a resource. If
+// there are no specific credentials for the resource, the global passphrase
+// and token are returned.
+func (s *server) resourceCredentials(resource string) (string, string) {
+	if s.credentials == nil {
+		return s.passphrase, s.token
+	}
+
+	credentials, ok := s.credentials(resource)
+	if !ok {
+		return s.passphrase, s.token
+	}
+
+	return credentials.Passphrase, credentials.Token
+}
+
+// sessionExtra returns the extra information for the session of the client.
+func (s *server) sessionExtra(client net.Addr) string {
+	ip, _, _ := net.SplitHostPort(client.String())
+
+	return sessionExtra(s.geo, ip)
+}
+
 func (s *server) handlePublish(conn srt.Conn) {
 	streamId := conn.StreamId()
 	client := conn.RemoteAddr()
@@ -556,26 +1176,53 @@ func (s *server) handlePublish(conn srt.Conn) {
 		si, _ = parseStreamId(streamId)
 	}
 
+	policy := s.takePolicy(client, streamId)
+
 	// Look for the stream
 	s.lock.Lock()
+	draining := s.draining
 	ch := s.channels[si.resource]
-	if ch == nil {
+	if ch == nil && !draining {
 		ch = newChannel(conn, si.resource, s.collector)
+		ch.maxSubscribers = s.maxSubscribers(policy)
 		s.channels[si.resource] = ch
 	} else {
 		ch = nil
 	}
 	s.lock.Unlock()
 
+	if draining {
+		s.log("PUBLISH", "DRAINING", si.resource, "server is shutting down", client)
+		conn.Close()
+		return
+	}
+
 	if ch == nil {
 		s.log("PUBLISH", "CONFLICT", si.resource, "already publishing", client)
 		conn.Close()
 		return
 	}
 
+	s.collector.Extra(si.resource, s.sessionExtra(client))
+
 	s.log("PUBLISH", "START", si.resource, "", client)
 
-	ch.pubsub.Publish(conn)
+	if s.recordPattern != nil && s.recordPattern.MatchString(si.resource) {
+		if err := s.startRecording(si.resource, ch); err != nil {
+			s.log("RECORD", "FAILED", si.resource, err.Error(), client)
+		}
+	}
+
+	if target, ok := s.relay.Targets[si.resource]; ok {
+		s.startRelay(si.resource, ch, target)
+	}
+
+	var pubconn srt.Conn = conn
+	if policy.MaxBitrate > 0 {
+		pubconn = newBitrateConn(conn, policy.MaxBitrate)
+	}
+
+	err := ch.pubsub.Publish(pubconn)
 
 	s.lock.Lock()
 	delete(s.channels, si.resource)
@@ -583,7 +1230,7 @@ func (s *server) handlePublish(conn srt.Conn) {
 
 	ch.Close()
 
-	s.log("PUBLISH", "STOP", si.resource, "", client)
+	s.logStop("PUBLISH", si.resource, err, client)
 
 	conn.Close()
 }
@@ -596,22 +1243,42 @@ func (s *server) handleSubscribe(conn srt.Conn) {
 
 	// Look for the stream
 	s.lock.RLock()
+	draining := s.draining
 	ch := s.channels[si.resource]
 	s.lock.RUnlock()
 
+	if draining {
+		s.log("SUBSCRIBE", "DRAINING", si.resource, "server is shutting down", client)
+		conn.Close()
+		return
+	}
+
 	if ch == nil {
 		s.log("SUBSCRIBE", "NOTFOUND", si.resource, "no publisher for this resource found", client)
 		conn.Close()
 		return
 	}
 
-	s.log("SUBSCRIBE", "START", si.resource, "", client)
+	if ch.IsFull() {
+		s.log("SUBSCRIBE", "LIMIT", si.resource, "max. number of subscribers reached", client)
+		conn.Close()
+		return
+	}
 
-	id := ch.AddSubscriber(conn, si.resource)
+	id, err := ch.AddSubscriber(conn, si.resource)
+	if err != nil {
+		s.log("SUBSCRIBE", "LIMIT", si.resource, err.Error(), client)
+		conn.Close()
+		return
+	}
+
+	s.collector.Extra(id, s.sessionExtra(client))
+
+	s.log("SUBSCRIBE", "START", si.resource, "", client)
 
-	ch.pubsub.Subscribe(conn)
+	err = ch.pubsub.Subscribe(conn)
 
-	s.log("SUBSCRIBE", "STOP", si.resource, "", client)
+	s.logStop("SUBSCRIBE", si.resource, err, client)
 
 	ch.RemoveSubscriber(id)
 
@@ -1,8 +1,25 @@
 package srt
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/datarhei/core/v16/io/fs"
+	"github.com/datarhei/core/v16/session"
+
+	srt "github.com/datarhei/gosrt"
+	"github.com/datarhei/gosrt/packet"
+	"github.com/datarhei/joy4/av"
+	"github.com/datarhei/joy4/codec/aacparser"
+	"github.com/datarhei/joy4/format/rtmp"
+	"github.com/datarhei/joy4/format/ts"
 	"github.com/stretchr/testify/require"
 )
 
@@ -42,3 +59,1062 @@ func TestParseOldStreamId(t *testing.T) {
 		require.Equal(t, wantsi, si)
 	}
 }
+
+type mockConn struct {
+	socketId uint32
+	streamId string
+	addr     net.Addr
+
+	payload []byte
+	stats   srt.Statistics
+
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newMockConn(socketId uint32, streamId, addr string) *mockConn {
+	udpaddr, _ := net.ResolveUDPAddr("udp", addr)
+
+	return &mockConn{
+		socketId: socketId,
+		streamId: streamId,
+		addr:     udpaddr,
+		closed:   make(chan struct{}),
+	}
+}
+
+func (c *mockConn) Read(p []byte) (int, error) {
+	<-c.closed
+	return 0, io.EOF
+}
+
+func (c *mockConn) ReadPacket() (packet.Packet, error) {
+	if c.payload == nil {
+		<-c.closed
+		return nil, io.EOF
+	}
+
+	select {
+	case <-c.closed:
+		return nil, io.EOF
+	default:
+	}
+
+	time.Sleep(time.Millisecond)
+
+	p := packet.NewPacket(c.addr)
+	p.SetData(c.payload)
+
+	return p, nil
+}
+
+func (c *mockConn) Write(p []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, io.EOF
+	default:
+	}
+
+	return len(p), nil
+}
+
+func (c *mockConn) WritePacket(p packet.Packet) error {
+	select {
+	case <-c.closed:
+		return io.EOF
+	default:
+	}
+
+	return nil
+}
+
+func (c *mockConn) Close() error {
+	c.once.Do(func() {
+		close(c.closed)
+	})
+
+	return nil
+}
+
+func (c *mockConn) LocalAddr() net.Addr                { return c.addr }
+func (c *mockConn) RemoteAddr() net.Addr               { return c.addr }
+func (c *mockConn) SetDeadline(t time.Time) error      { return nil }
+func (c *mockConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *mockConn) SetWriteDeadline(t time.Time) error { return nil }
+func (c *mockConn) SocketId() uint32                   { return c.socketId }
+func (c *mockConn) PeerSocketId() uint32               { return c.socketId }
+func (c *mockConn) StreamId() string                   { return c.streamId }
+func (c *mockConn) Stats(s *srt.Statistics)            { *s = c.stats }
+func (c *mockConn) Version() uint32                    { return 5 }
+
+func newTestServer(t *testing.T, config Config) *server {
+	s, err := New(config)
+	require.NoError(t, err)
+
+	return s.(*server)
+}
+
+func TestCloseConnection(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	err := s.CloseConnection(42)
+	require.Error(t, err)
+
+	err = s.CloseConnection(2)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, ok := s.Channels().Publisher["foobar"]
+	require.True(t, ok)
+
+	err = s.CloseConnection(1)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := s.Channels().Publisher["foobar"]
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCloseChannel(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	err := s.CloseChannel("foobar")
+	require.Error(t, err)
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	err = s.CloseChannel("foobar")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := s.Channels().Publisher["foobar"]
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-sub.closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "subscriber connection has not been closed")
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	defaultConfig := srt.DefaultConfig()
+
+	require.Equal(t, defaultConfig.ConnectionTimeout, s.server.Config.ConnectionTimeout)
+	require.Equal(t, defaultConfig.PeerIdleTimeout, s.server.Config.PeerIdleTimeout)
+
+	s = newTestServer(t, Config{
+		ConnectionTimeout: 10 * time.Second,
+		PeerIdleTimeout:   15 * time.Second,
+	})
+
+	require.Equal(t, 10*time.Second, s.server.Config.ConnectionTimeout)
+	require.Equal(t, 15*time.Second, s.server.Config.PeerIdleTimeout)
+}
+
+func TestStopReason(t *testing.T) {
+	require.Equal(t, "eof", stopReason(nil))
+	require.Equal(t, "eof", stopReason(io.EOF))
+	require.Equal(t, "eof", stopReason(fmt.Errorf("read: %w", io.EOF)))
+	require.Equal(t, "error", stopReason(fmt.Errorf("connection reset")))
+}
+
+type mockConnRequest struct {
+	addr       net.Addr
+	streamId   string
+	passphrase string
+}
+
+func newMockConnRequest(streamId, addr string) *mockConnRequest {
+	udpaddr, _ := net.ResolveUDPAddr("udp", addr)
+
+	return &mockConnRequest{
+		addr:     udpaddr,
+		streamId: streamId,
+	}
+}
+
+func (r *mockConnRequest) RemoteAddr() net.Addr                          { return r.addr }
+func (r *mockConnRequest) Version() uint32                               { return 5 }
+func (r *mockConnRequest) StreamId() string                              { return r.streamId }
+func (r *mockConnRequest) IsEncrypted() bool                             { return len(r.passphrase) != 0 }
+func (r *mockConnRequest) SetRejectionReason(reason srt.RejectionReason) {}
+
+func (r *mockConnRequest) SetPassphrase(p string) error {
+	if p != r.passphrase {
+		return fmt.Errorf("invalid passphrase")
+	}
+
+	return nil
+}
+
+func TestAuth(t *testing.T) {
+	s := newTestServer(t, Config{
+		Token: "secret",
+		Auth: func(resource, token string, publish bool, client net.Addr) (Policy, error) {
+			if token != "alice" {
+				return Policy{}, fmt.Errorf("unknown user")
+			}
+
+			return Policy{
+				Resources: []string{"alice/*"},
+			}, nil
+		},
+	})
+
+	mode := s.handleConnect(newMockConnRequest("alice/live,mode:publish,token:secret", "127.0.0.1:6000"))
+	require.Equal(t, srt.REJECT, mode)
+
+	mode = s.handleConnect(newMockConnRequest("bob/live,mode:publish,token:alice", "127.0.0.1:6000"))
+	require.Equal(t, srt.REJECT, mode)
+
+	mode = s.handleConnect(newMockConnRequest("alice/live,mode:publish,token:alice", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+}
+
+func TestPolicyMaxBitrate(t *testing.T) {
+	s := newTestServer(t, Config{
+		Auth: func(resource, token string, publish bool, client net.Addr) (Policy, error) {
+			return Policy{
+				MaxBitrate: 100_000,
+			}, nil
+		},
+	})
+
+	mode := s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+
+	// About 1316 bytes every millisecond are roughly 10 Mbit/s
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+
+	done := make(chan struct{})
+
+	go func() {
+		s.handlePublish(pub)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "publisher exceeding the bitrate limit has not been stopped")
+	}
+
+	require.Empty(t, s.Channels().Publisher)
+}
+
+func TestPolicyExpire(t *testing.T) {
+	s := newTestServer(t, Config{
+		Auth: func(resource, token string, publish bool, client net.Addr) (Policy, error) {
+			return Policy{
+				MaxBitrate: 100_000,
+			}, nil
+		},
+	})
+
+	s.policyTimeout = 50 * time.Millisecond
+
+	mode := s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+	require.Equal(t, 1, len(s.policies))
+
+	time.Sleep(100 * time.Millisecond)
+
+	// The first request never turned into a connection
+	mode = s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6001"))
+	require.Equal(t, srt.PUBLISH, mode)
+	require.Equal(t, 1, len(s.policies))
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6001")
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Empty(t, s.policies)
+
+	// The policies of subscribers are not stored
+	mode = s.handleConnect(newMockConnRequest("foobar", "127.0.0.1:6002"))
+	require.Equal(t, srt.SUBSCRIBE, mode)
+	require.Empty(t, s.policies)
+
+	pub.Close()
+}
+
+func TestPolicyMaxSubscribers(t *testing.T) {
+	s := newTestServer(t, Config{
+		Auth: func(resource, token string, publish bool, client net.Addr) (Policy, error) {
+			return Policy{
+				MaxSubscribers: 1,
+			}, nil
+		},
+	})
+
+	mode := s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mode = s.handleConnect(newMockConnRequest("foobar", "127.0.0.1:6001"))
+	require.Equal(t, srt.SUBSCRIBE, mode)
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mode = s.handleConnect(newMockConnRequest("foobar", "127.0.0.1:6002"))
+	require.Equal(t, srt.REJECT, mode)
+
+	pub.Close()
+}
+
+func TestSessionExtra(t *testing.T) {
+	extra := sessionExtra(nil, "192.168.1.42")
+	require.Equal(t, "[192.168.1.0]", extra)
+
+	resolver := GeoResolverFunc(func(ip string) (GeoInfo, error) {
+		if ip != "192.168.1.42" {
+			return GeoInfo{}, fmt.Errorf("unknown IP")
+		}
+
+		return GeoInfo{
+			Country:      "CH",
+			ASN:          3303,
+			Organization: "Swisscom",
+		}, nil
+	})
+
+	extra = sessionExtra(resolver, "192.168.1.42")
+	require.Equal(t, "[192.168.1.0] country:CH,asn:3303,org:Swisscom", extra)
+
+	extra = sessionExtra(resolver, "10.0.0.1")
+	require.Equal(t, "[10.0.0.0]", extra)
+}
+
+func TestGeoResolver(t *testing.T) {
+	collector := session.NewCollector(session.CollectorConfig{})
+
+	s := newTestServer(t, Config{
+		Collector: collector,
+		GeoResolver: GeoResolverFunc(func(ip string) (GeoInfo, error) {
+			return GeoInfo{Country: "CH"}, nil
+		}),
+	})
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		for _, sess := range collector.Active() {
+			if sess.ID == "foobar" && sess.Extra == "[127.0.0.0] country:CH" {
+				return true
+			}
+		}
+
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	pub.Close()
+}
+
+func TestToken(t *testing.T) {
+	s := newTestServer(t, Config{
+		Token: "foobar",
+	})
+
+	mode := s.handleConnect(newMockConnRequest("foobar,mode:publish,token:foobar", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+
+	mode = s.handleConnect(newMockConnRequest("foobar,mode:publish,token:foobaz", "127.0.0.1:6000"))
+	require.Equal(t, srt.REJECT, mode)
+
+	mode = s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6000"))
+	require.Equal(t, srt.REJECT, mode)
+}
+
+func TestCredentials(t *testing.T) {
+	s := newTestServer(t, Config{
+		Token: "global",
+		Credentials: func(resource string) (Credentials, bool) {
+			if resource != "foobar" {
+				return Credentials{}, false
+			}
+
+			return Credentials{
+				Passphrase: "foobarpassphrase",
+				Token:      "foobartoken",
+			}, true
+		},
+	})
+
+	req := newMockConnRequest("foobar,mode:publish,token:foobartoken", "127.0.0.1:6000")
+	req.passphrase = "foobarpassphrase"
+	require.Equal(t, srt.PUBLISH, s.handleConnect(req))
+
+	req = newMockConnRequest("foobar,mode:publish,token:foobartoken", "127.0.0.1:6000")
+	require.Equal(t, srt.REJECT, s.handleConnect(req), "connection has to be encrypted")
+
+	req = newMockConnRequest("foobar,mode:publish,token:foobartoken", "127.0.0.1:6000")
+	req.passphrase = "wrongpassphrase"
+	require.Equal(t, srt.REJECT, s.handleConnect(req), "wrong passphrase")
+
+	req = newMockConnRequest("foobar,mode:publish,token:global", "127.0.0.1:6000")
+	req.passphrase = "foobarpassphrase"
+	require.Equal(t, srt.REJECT, s.handleConnect(req), "global token is not valid")
+
+	// Other resources fall back to the global token
+	req = newMockConnRequest("bazfoo,mode:publish,token:global", "127.0.0.1:6000")
+	require.Equal(t, srt.PUBLISH, s.handleConnect(req))
+
+	req = newMockConnRequest("bazfoo,mode:publish,token:foobartoken", "127.0.0.1:6000")
+	require.Equal(t, srt.REJECT, s.handleConnect(req))
+}
+
+func TestMaxSubscribersPerChannel(t *testing.T) {
+	s := newTestServer(t, Config{
+		MaxSubscribersPerChannel: 2,
+	})
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 100)
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub1 := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub1)
+
+	sub2 := newMockConn(3, "foobar", "127.0.0.1:6002")
+	go s.handleSubscribe(sub2)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	sub3 := newMockConn(4, "foobar", "127.0.0.1:6003")
+	s.handleSubscribe(sub3)
+
+	require.Equal(t, 2, len(s.Channels().Subscriber["foobar"]))
+
+	// Removing a subscriber frees a slot
+	sub1.Close()
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub4 := newMockConn(5, "foobar", "127.0.0.1:6004")
+	go s.handleSubscribe(sub4)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	pub.Close()
+}
+
+func TestMaxSubscribers(t *testing.T) {
+	s := newTestServer(t, Config{})
+	require.Equal(t, 0, s.maxSubscribers(Policy{}))
+	require.Equal(t, 3, s.maxSubscribers(Policy{MaxSubscribers: 3}))
+
+	s = newTestServer(t, Config{MaxSubscribersPerChannel: 5})
+	require.Equal(t, 5, s.maxSubscribers(Policy{}))
+	require.Equal(t, 3, s.maxSubscribers(Policy{MaxSubscribers: 3}))
+	require.Equal(t, 5, s.maxSubscribers(Policy{MaxSubscribers: 10}))
+}
+
+func TestChannelSummaries(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	require.Equal(t, []ChannelSummary{}, s.ChannelSummaries())
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.stats.Accumulated.ByteRecv = 1000
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	subs := []*mockConn{}
+
+	for i := 0; i < 3; i++ {
+		sub := newMockConn(uint32(2+i), "foobar", fmt.Sprintf("127.0.0.1:%d", 6001+i))
+		sub.stats.Accumulated.ByteSent = 100
+		subs = append(subs, sub)
+
+		_, err := s.channels["foobar"].AddSubscriber(sub, "foobar")
+		require.NoError(t, err)
+	}
+
+	s.channels["foobar"].RemoveSubscriber(subs[0].RemoteAddr().String())
+
+	summaries := s.ChannelSummaries()
+	require.Equal(t, 1, len(summaries))
+
+	summary := summaries[0]
+	require.Equal(t, "foobar", summary.Resource)
+	require.Equal(t, uint64(1000), summary.RxBytes)
+	require.Equal(t, uint64(200), summary.TxBytes)
+	require.Equal(t, 2, summary.Subscribers)
+	require.Equal(t, 3, summary.PeakSubscribers)
+	require.Greater(t, summary.Uptime, time.Duration(0))
+
+	pub.Close()
+}
+
+func TestCIDR(t *testing.T) {
+	s := newTestServer(t, Config{
+		AllowCIDR: []string{"127.0.0.0/8"},
+	})
+
+	mode := s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+
+	mode = s.handleConnect(newMockConnRequest("foobar,mode:publish", "192.168.1.1:6000"))
+	require.Equal(t, srt.REJECT, mode)
+
+	s = newTestServer(t, Config{
+		AllowCIDR: []string{"127.0.0.0/8"},
+		DenyCIDR:  []string{"127.0.0.2/32"},
+	})
+
+	mode = s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.1:6000"))
+	require.Equal(t, srt.PUBLISH, mode)
+
+	mode = s.handleConnect(newMockConnRequest("foobar,mode:publish", "127.0.0.2:6000"))
+	require.Equal(t, srt.REJECT, mode)
+
+	_, err := New(Config{
+		AllowCIDR: []string{"127.0.0.0/33"},
+	})
+	require.Error(t, err)
+
+	_, err = New(Config{
+		DenyCIDR: []string{"foobar"},
+	})
+	require.Error(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	memfs, err := fs.NewMemFilesystem(fs.MemConfig{})
+	require.NoError(t, err)
+
+	s := newTestServer(t, Config{
+		Record: RecordConfig{
+			Filesystem: memfs,
+			Pattern:    "^live/",
+			Path:       "/recordings",
+			MaxSize:    10 * 1316,
+		},
+	})
+
+	pub := newMockConn(1, "live/foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+
+	go s.handlePublish(pub)
+
+	// The recording is rotated after 10 packets
+	require.Eventually(t, func() bool {
+		return len(memfs.List("/recordings/live_foobar", "")) >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	for _, f := range memfs.List("/recordings/live_foobar", "") {
+		require.Equal(t, int64(10*1316), f.Size())
+	}
+
+	err = s.StartRecording("live/foobar")
+	require.Error(t, err)
+
+	err = s.StopRecording("live/foobar")
+	require.NoError(t, err)
+
+	err = s.StopRecording("live/foobar")
+	require.Error(t, err)
+
+	files := len(memfs.List("/recordings/live_foobar", ""))
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, files, len(memfs.List("/recordings/live_foobar", "")))
+
+	pub.Close()
+}
+
+func TestRecordRestart(t *testing.T) {
+	memfs, err := fs.NewMemFilesystem(fs.MemConfig{})
+	require.NoError(t, err)
+
+	s := newTestServer(t, Config{
+		Record: RecordConfig{
+			Filesystem: memfs,
+			Pattern:    "^live/",
+			MaxSize:    10 * 1316,
+		},
+	})
+
+	pub := newMockConn(1, "live/foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+
+	go s.handlePublish(pub)
+
+	recorder := func() *recorder {
+		s.lock.RLock()
+		ch := s.channels["live/foobar"]
+		s.lock.RUnlock()
+
+		if ch == nil {
+			return nil
+		}
+
+		ch.lock.RLock()
+		defer ch.lock.RUnlock()
+
+		return ch.recorder
+	}
+
+	require.Eventually(t, func() bool {
+		return recorder() != nil
+	}, time.Second, 10*time.Millisecond)
+
+	first := recorder()
+
+	for i := 0; i < 3; i++ {
+		err = s.StopRecording("live/foobar")
+		require.NoError(t, err)
+
+		err = s.StartRecording("live/foobar")
+		require.NoError(t, err)
+	}
+
+	rec := recorder()
+	require.NotNil(t, rec)
+	require.NotEqual(t, first.SocketId(), rec.SocketId())
+
+	// The new recorder keeps receiving packets
+	require.Eventually(t, func() bool {
+		rec.lock.Lock()
+		defer rec.lock.Unlock()
+
+		return rec.seq >= 3
+	}, 3*time.Second, 10*time.Millisecond)
+
+	pub.Close()
+}
+
+func TestRecordStart(t *testing.T) {
+	memfs, err := fs.NewMemFilesystem(fs.MemConfig{})
+	require.NoError(t, err)
+
+	s := newTestServer(t, Config{
+		Record: RecordConfig{
+			Filesystem:  memfs,
+			Pattern:     "^live/",
+			MaxDuration: 50 * time.Millisecond,
+		},
+	})
+
+	err = s.StartRecording("foobar")
+	require.Error(t, err)
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+
+	done := make(chan struct{})
+
+	go func() {
+		s.handlePublish(pub)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// The resource doesn't match the pattern
+	time.Sleep(100 * time.Millisecond)
+	require.Empty(t, memfs.List("/", ""))
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	err = s.StartRecording("foobar")
+	require.NoError(t, err)
+
+	// The recording is rotated every 50ms
+	require.Eventually(t, func() bool {
+		return len(memfs.List("/foobar", "")) >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	pub.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "publisher has not been stopped")
+	}
+
+	require.Eventually(t, func() bool {
+		for _, f := range memfs.List("/foobar", "") {
+			if f.Size() == 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-sub.closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "subscriber connection has not been closed")
+	}
+
+	_, err = New(Config{
+		Record: RecordConfig{
+			Filesystem: memfs,
+			Pattern:    "[",
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestDrain(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error)
+
+	go func() {
+		done <- s.Drain(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return s.isDraining()
+	}, time.Second, 10*time.Millisecond)
+
+	mode := s.handleConnect(newMockConnRequest("foobar", "127.0.0.1:6002"))
+	require.Equal(t, srt.REJECT, mode)
+
+	mode = s.handleConnect(newMockConnRequest("barfoo,mode:publish", "127.0.0.1:6003"))
+	require.Equal(t, srt.REJECT, mode)
+
+	// Drain waits for the subscriber
+	select {
+	case <-done:
+		require.Fail(t, "drain didn't wait for the subscriber")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	sub.Close()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "drain didn't finish after the subscriber left")
+	}
+
+	select {
+	case <-pub.closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "publisher connection has not been closed")
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err := s.Drain(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.Less(t, time.Since(start), 2*time.Second)
+
+	pub.Close()
+	sub.Close()
+}
+
+// tsConn is a publishing connection that sends an MPEG-TS stream with
+// an AAC audio track. It blocks after the stream has been sent.
+type tsConn struct {
+	*mockConn
+
+	data []byte
+}
+
+func newTSConn(t *testing.T, socketId uint32, streamId, addr string) *tsConn {
+	codec, err := aacparser.NewCodecDataFromMPEG4AudioConfig(aacparser.MPEG4AudioConfig{
+		SampleRate:    44100,
+		ChannelLayout: av.CH_STEREO,
+		ObjectType:    aacparser.AOT_AAC_LC,
+	})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+
+	muxer := ts.NewMuxer(buf)
+	err = muxer.WriteHeader([]av.CodecData{codec})
+	require.NoError(t, err)
+
+	for i := 0; i < 500; i++ {
+		err = muxer.WritePacket(av.Packet{
+			Time: time.Duration(i) * 1024 * time.Second / 44100,
+			Data: make([]byte, 100),
+		})
+		require.NoError(t, err)
+	}
+
+	return &tsConn{
+		mockConn: newMockConn(socketId, streamId, addr),
+		data:     buf.Bytes(),
+	}
+}
+
+func (c *tsConn) ReadPacket() (packet.Packet, error) {
+	if len(c.data) == 0 {
+		<-c.closed
+		return nil, io.EOF
+	}
+
+	select {
+	case <-c.closed:
+		return nil, io.EOF
+	default:
+	}
+
+	time.Sleep(time.Millisecond)
+
+	n := min(len(c.data), 1316)
+
+	p := packet.NewPacket(c.addr)
+	p.SetData(c.data[:n])
+
+	c.data = c.data[n:]
+
+	return p, nil
+}
+
+func TestRelayConfig(t *testing.T) {
+	_, err := New(Config{
+		Relay: RelayConfig{
+			Targets: map[string]string{"live/foobar": "rtmp://127.0.0.1/live/foobar"},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, target := range []string{"http://127.0.0.1/live/foobar", "rtmp:///live/foobar", "rtmp://[::1"} {
+		_, err := New(Config{
+			Relay: RelayConfig{
+				Targets: map[string]string{"live/foobar": target},
+			},
+		})
+		require.Error(t, err, target)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	streams := make(chan []av.CodecData, 1)
+	done := make(chan struct{})
+	packets := atomic.Int64{}
+
+	sink := &rtmp.Server{
+		HandlePublish: func(conn *rtmp.Conn) {
+			defer close(done)
+
+			s, err := conn.Streams()
+			if err != nil {
+				return
+			}
+
+			streams <- s
+
+			for {
+				if _, err := conn.ReadPacket(); err != nil {
+					return
+				}
+
+				packets.Add(1)
+			}
+		},
+	}
+
+	go sink.Serve(ln)
+	defer sink.Close()
+
+	s := newTestServer(t, Config{
+		Relay: RelayConfig{
+			Targets: map[string]string{"live/foobar": "rtmp://" + ln.Addr().String() + "/live/foobar"},
+		},
+	})
+
+	pub := newTSConn(t, 1, "live/foobar,mode:publish", "127.0.0.1:6000")
+	go s.handlePublish(pub)
+
+	other := newTSConn(t, 2, "live/other,mode:publish", "127.0.0.1:6001")
+	go s.handlePublish(other)
+	defer other.Close()
+
+	select {
+	case st := <-streams:
+		require.Equal(t, 1, len(st))
+		require.Equal(t, av.AAC, st[0].Type())
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "relay didn't publish to the RTMP server")
+	}
+
+	require.Eventually(t, func() bool {
+		return packets.Load() >= 10
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// The relay stops with the publisher
+	pub.Close()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "relay didn't stop")
+	}
+
+	require.Eventually(t, func() bool {
+		_, ok := s.Channels().Publisher["live/foobar"]
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRelayFailure(t *testing.T) {
+	// Nothing is listening on the target
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s := newTestServer(t, Config{
+		Relay: RelayConfig{
+			Targets: map[string]string{"foobar": "rtmp://" + addr + "/live/foobar"},
+		},
+	})
+
+	pub := newMockConn(1, "foobar,mode:publish", "127.0.0.1:6000")
+	pub.payload = make([]byte, 1316)
+	go s.handlePublish(pub)
+
+	require.Eventually(t, func() bool {
+		return s.Channels().Publisher["foobar"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	sub := newMockConn(2, "foobar", "127.0.0.1:6001")
+	go s.handleSubscribe(sub)
+
+	require.Eventually(t, func() bool {
+		return len(s.Channels().Subscriber["foobar"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// The failed relay is gone, the publisher and the subscriber are not affected
+	require.Eventually(t, func() bool {
+		s.lock.RLock()
+		ch := s.channels["foobar"]
+		s.lock.RUnlock()
+
+		ch.lock.RLock()
+		defer ch.lock.RUnlock()
+
+		return ch.relay == nil
+	}, 3*time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, uint32(1), s.Channels().Publisher["foobar"])
+	require.Equal(t, 1, len(s.Channels().Subscriber["foobar"]))
+
+	pub.Close()
+}